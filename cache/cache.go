@@ -0,0 +1,356 @@
+// Package cache is a typed cache-aside layer over Redis. A Cache[T] loads
+// missing values through a loader function, collapses concurrent misses for
+// the same key into one load, caches not-found results for a short time,
+// spreads expiry times with jitter, and reports misses, loader failures and
+// Redis outages as distinct errors. Optionally it protects hot keys from a
+// stampede of recomputations when they expire, see Stampede.
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMiss is returned by Get when the key is not cached
+var ErrMiss = errors.New("cache: miss")
+
+// ErrNotFound is returned by loaders for keys that do not exist. It is
+// cached for Options.NegativeTTL so repeated lookups skip the loader.
+var ErrNotFound = errors.New("cache: not found")
+
+// LoaderError is a loader failure other than ErrNotFound
+type LoaderError struct {
+	Key string
+	Err error
+}
+
+func (e *LoaderError) Error() string {
+	return fmt.Sprintf("cache: loading %q: %v", e.Key, e.Err)
+}
+
+func (e *LoaderError) Unwrap() error {
+	return e.Err
+}
+
+// OutageError means Redis could not be reached, as opposed to a miss or an
+// error reply from the server
+type OutageError struct {
+	Op  string
+	Err error
+}
+
+func (e *OutageError) Error() string {
+	return fmt.Sprintf("cache: redis unavailable during %s: %v", e.Op, e.Err)
+}
+
+func (e *OutageError) Unwrap() error {
+	return e.Err
+}
+
+// Loader fetches the value for key from the source of truth
+type Loader[T any] func(ctx context.Context, key string) (T, error)
+
+// Options configures a Cache
+type Options struct {
+	// Prefix is prepended to keys, e.g. "cache:user:"
+	Prefix string
+	// TTL is how long loaded values stay cached, it defaults to 1 minute
+	TTL time.Duration
+	// Jitter randomizes each TTL by up to this fraction either way, so keys
+	// written together do not all expire together. 0.1 means ±10%.
+	Jitter float64
+	// NegativeTTL is how long ErrNotFound is cached, 0 disables it
+	NegativeTTL time.Duration
+	// Codec encodes values, it defaults to JSON
+	Codec Codec
+	// FailOpen makes Fetch call the loader directly when Redis is down,
+	// instead of returning an OutageError
+	FailOpen bool
+
+	// Stampede picks how Fetch keeps many clients from recomputing a key
+	// at once when it expires
+	Stampede Stampede
+	// Beta tunes StampedeXFetch, above 1 refreshes earlier and below 1
+	// later, it defaults to 1
+	Beta float64
+	// LockTTL bounds how long StampedeLock holds the recompute lock and how
+	// long other callers wait for the result, it defaults to 5s
+	LockTTL time.Duration
+}
+
+// Stats counts what a Cache has done so far
+type Stats struct {
+	Hits         int64
+	Misses       int64
+	NegativeHits int64
+	Loads        int64
+	Shared       int64
+	Outages      int64
+	SetErrors    int64
+	// EarlyRefreshes counts XFetch recomputations before expiry
+	EarlyRefreshes int64
+	// LockWaits counts callers that waited for another's recompute
+	LockWaits int64
+}
+
+// Cached values start with a marker byte, so a not-found entry needs no
+// help from the codec. Timed values also record how long they took to load,
+// as a uvarint of microseconds, for XFetch.
+const (
+	markValue    = 'v'
+	markTimed    = 'd'
+	markNotFound = 'n'
+)
+
+// entry is a decoded cached value
+type entry[T any] struct {
+	value T
+	// delta is how long the value took to load, zero if unknown
+	delta time.Duration
+	// ttl is the remaining lifetime, only looked up for XFetch
+	ttl time.Duration
+}
+
+// Cache is a typed cache for values of type T
+type Cache[T any] struct {
+	rdb    redis.Cmdable
+	opts   Options
+	loader Loader[T]
+	flight flight[T]
+
+	hits, misses, negativeHits, loads, shared, outages, setErrors atomic.Int64
+	earlyRefreshes, lockWaits                                     atomic.Int64
+}
+
+// New returns a Cache that fills misses with loader, filling in defaults for
+// unset options
+func New[T any](rdb redis.Cmdable, loader Loader[T], opts Options) *Cache[T] {
+	if opts.TTL <= 0 {
+		opts.TTL = time.Minute
+	}
+	if opts.Codec == nil {
+		opts.Codec = JSON
+	}
+	if opts.Beta <= 0 {
+		opts.Beta = 1
+	}
+	if opts.LockTTL <= 0 {
+		opts.LockTTL = 5 * time.Second
+	}
+	return &Cache[T]{rdb: rdb, opts: opts, loader: loader}
+}
+
+// Stats returns a snapshot of the cache's counters
+func (c *Cache[T]) Stats() Stats {
+	return Stats{
+		Hits:         c.hits.Load(),
+		Misses:       c.misses.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Loads:        c.loads.Load(),
+		Shared:       c.shared.Load(),
+		Outages:      c.outages.Load(),
+		SetErrors:    c.setErrors.Load(),
+
+		EarlyRefreshes: c.earlyRefreshes.Load(),
+		LockWaits:      c.lockWaits.Load(),
+	}
+}
+
+// Get returns the cached value for key. It returns ErrMiss if nothing is
+// cached, ErrNotFound for a cached not-found result and an *OutageError if
+// Redis is unreachable.
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, error) {
+	e, err := c.lookup(ctx, key, false)
+	return e.value, err
+}
+
+// lookup is read counted as a hit, miss or negative hit. Call it once per
+// Get or Fetch, rechecks along the way use read.
+func (c *Cache[T]) lookup(ctx context.Context, key string, withTTL bool) (entry[T], error) {
+	e, err := c.read(ctx, key, withTTL)
+	switch err {
+	case nil:
+		c.hits.Add(1)
+	case ErrMiss:
+		c.misses.Add(1)
+	case ErrNotFound:
+		c.negativeHits.Add(1)
+	}
+	return e, err
+}
+
+// read reads and decodes the entry for key, with its remaining TTL if
+// withTTL is set
+func (c *Cache[T]) read(ctx context.Context, key string, withTTL bool) (entry[T], error) {
+	var e entry[T]
+	var get *redis.StringCmd
+	if withTTL {
+		var pttl *redis.DurationCmd
+		c.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			get = pipe.Get(ctx, c.opts.Prefix+key)
+			pttl = pipe.PTTL(ctx, c.opts.Prefix+key)
+			return nil
+		})
+		e.ttl = pttl.Val()
+	} else {
+		get = c.rdb.Get(ctx, c.opts.Prefix+key)
+	}
+
+	data, err := get.Bytes()
+	switch {
+	case err == redis.Nil:
+		return e, ErrMiss
+	case err != nil:
+		return e, c.redisError("GET", err)
+	case len(data) == 0:
+		return e, fmt.Errorf("cache: %q holds an empty entry", key)
+	}
+
+	payload := data[1:]
+	switch data[0] {
+	case markNotFound:
+		return e, ErrNotFound
+	case markTimed:
+		micros, n := binary.Uvarint(payload)
+		if n <= 0 {
+			return e, fmt.Errorf("cache: %q holds a corrupt entry", key)
+		}
+		e.delta = time.Duration(micros) * time.Microsecond
+		payload = payload[n:]
+	}
+	if err := c.opts.Codec.Unmarshal(payload, &e.value); err != nil {
+		return e, fmt.Errorf("cache: decoding %q: %w", key, err)
+	}
+	return e, nil
+}
+
+// Fetch returns the cached value for key, loading and caching it on a miss.
+// Concurrent misses for the same key share one load. A failed write back to
+// Redis is counted in Stats but does not fail the call.
+func (c *Cache[T]) Fetch(ctx context.Context, key string) (T, error) {
+	e, err := c.lookup(ctx, key, c.opts.Stampede == StampedeXFetch)
+	var outage *OutageError
+	switch {
+	case err == nil:
+		if c.opts.Stampede == StampedeXFetch && c.refreshEarly(e) {
+			c.earlyRefreshes.Add(1)
+			// Keep serving the cached value if the refresh fails
+			if v, err := c.load(ctx, key); err == nil {
+				return v, nil
+			}
+		}
+		return e.value, nil
+	case err == ErrNotFound:
+		return e.value, err
+	case errors.As(err, &outage):
+		if !c.opts.FailOpen {
+			return e.value, err
+		}
+		return c.load(ctx, key)
+	case err != ErrMiss:
+		return e.value, err
+	}
+
+	if c.opts.Stampede == StampedeLock {
+		return c.loadLocked(ctx, key)
+	}
+	return c.load(ctx, key)
+}
+
+// load calls the loader once for all concurrent callers of key
+func (c *Cache[T]) load(ctx context.Context, key string) (T, error) {
+	v, err, shared := c.flight.do(key, func() (T, error) {
+		return c.loadUnshared(ctx, key)
+	})
+	if shared {
+		c.shared.Add(1)
+	}
+	return v, err
+}
+
+// loadUnshared calls the loader and caches the result along with how long
+// it took
+func (c *Cache[T]) loadUnshared(ctx context.Context, key string) (T, error) {
+	c.loads.Add(1)
+	start := time.Now()
+	v, err := c.loader(ctx, key)
+	switch {
+	case err == nil:
+		if err := c.set(ctx, key, v, time.Since(start)); err != nil {
+			c.setErrors.Add(1)
+		}
+	case errors.Is(err, ErrNotFound):
+		c.storeNotFound(ctx, key)
+		err = ErrNotFound
+	default:
+		err = &LoaderError{Key: key, Err: err}
+	}
+	return v, err
+}
+
+// Set caches v for key with the jittered TTL
+func (c *Cache[T]) Set(ctx context.Context, key string, v T) error {
+	return c.set(ctx, key, v, 0)
+}
+
+// set caches v, recording delta as its load time when it is known
+func (c *Cache[T]) set(ctx context.Context, key string, v T, delta time.Duration) error {
+	data, err := c.opts.Codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cache: encoding %q: %w", key, err)
+	}
+	buf := []byte{markValue}
+	if delta > 0 {
+		buf = binary.AppendUvarint([]byte{markTimed}, uint64(delta.Microseconds()))
+	}
+	buf = append(buf, data...)
+	if err := c.rdb.Set(ctx, c.opts.Prefix+key, buf, c.ttl(c.opts.TTL)).Err(); err != nil {
+		return c.redisError("SET", err)
+	}
+	return nil
+}
+
+// Delete removes key from the cache
+func (c *Cache[T]) Delete(ctx context.Context, key string) error {
+	if err := c.rdb.Del(ctx, c.opts.Prefix+key).Err(); err != nil {
+		return c.redisError("DEL", err)
+	}
+	return nil
+}
+
+func (c *Cache[T]) storeNotFound(ctx context.Context, key string) {
+	if c.opts.NegativeTTL <= 0 {
+		return
+	}
+	err := c.rdb.Set(ctx, c.opts.Prefix+key, []byte{markNotFound}, c.ttl(c.opts.NegativeTTL)).Err()
+	if err != nil {
+		c.setErrors.Add(1)
+	}
+}
+
+// ttl spreads d by up to Jitter either way
+func (c *Cache[T]) ttl(d time.Duration) time.Duration {
+	if c.opts.Jitter <= 0 {
+		return d
+	}
+	spread := (rand.Float64()*2 - 1) * c.opts.Jitter
+	return max(time.Duration(float64(d)*(1+spread)), time.Millisecond)
+}
+
+// redisError wraps err as an *OutageError unless the server itself replied
+// with an error
+func (c *Cache[T]) redisError(op string, err error) error {
+	var reply redis.Error
+	if errors.As(err, &reply) {
+		return fmt.Errorf("cache: %s: %w", op, err)
+	}
+	c.outages.Add(1)
+	return &OutageError{Op: op, Err: err}
+}
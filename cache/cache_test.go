@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newClient(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+// countingLoader returns "value-<key>", or ErrNotFound for "missing", and
+// counts its calls. Each call waits on release when it is not nil.
+func countingLoader(calls *atomic.Int64, release <-chan struct{}) Loader[string] {
+	return func(ctx context.Context, key string) (string, error) {
+		calls.Add(1)
+		if release != nil {
+			<-release
+		}
+		if key == "missing" {
+			return "", ErrNotFound
+		}
+		return "value-" + key, nil
+	}
+}
+
+func TestGetCounts(t *testing.T) {
+	rdb := newClient(t)
+	ctx := context.Background()
+	var calls atomic.Int64
+	c := New(rdb, countingLoader(&calls, nil), Options{Prefix: "t:", NegativeTTL: time.Minute})
+
+	if _, err := c.Get(ctx, "a"); err != ErrMiss {
+		t.Fatalf("Get before Set = %v, want ErrMiss", err)
+	}
+	if err := c.Set(ctx, "a", "x"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if v, err := c.Get(ctx, "a"); err != nil || v != "x" {
+			t.Fatalf("Get = %q, %v, want x", v, err)
+		}
+	}
+	if _, err := c.Fetch(ctx, "missing"); err != ErrNotFound {
+		t.Fatalf("Fetch(missing) = %v, want ErrNotFound", err)
+	}
+	if _, err := c.Get(ctx, "missing"); err != ErrNotFound {
+		t.Fatalf("Get(missing) = %v, want the cached ErrNotFound", err)
+	}
+
+	want := Stats{Hits: 3, Misses: 2, NegativeHits: 1, Loads: 1}
+	if s := c.Stats(); s != want {
+		t.Errorf("stats = %+v, want %+v", s, want)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1", calls.Load())
+	}
+}
+
+func TestFetchCounts(t *testing.T) {
+	for _, strategy := range []Stampede{StampedeNone, StampedeXFetch, StampedeLock} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			rdb := newClient(t)
+			ctx := context.Background()
+			var calls atomic.Int64
+			c := New(rdb, countingLoader(&calls, nil), Options{Prefix: "t:", Stampede: strategy})
+
+			for i := 0; i < 3; i++ {
+				if v, err := c.Fetch(ctx, "a"); err != nil || v != "value-a" {
+					t.Fatalf("Fetch = %q, %v", v, err)
+				}
+			}
+			// One miss that loads, then two hits
+			s := c.Stats()
+			if s.Misses != 1 || s.Hits != 2 || s.Loads != 1 {
+				t.Errorf("stats = %+v, want 1 miss, 2 hits and 1 load", s)
+			}
+		})
+	}
+}
+
+func TestFetchSharesConcurrentLoads(t *testing.T) {
+	for _, strategy := range []Stampede{StampedeNone, StampedeLock} {
+		t.Run(strategy.String(), func(t *testing.T) {
+			rdb := newClient(t)
+			var calls atomic.Int64
+			release := make(chan struct{})
+			c := New(rdb, countingLoader(&calls, release), Options{Prefix: "t:", Stampede: strategy})
+
+			const callers = 5
+			var wg sync.WaitGroup
+			errs := make(chan error, callers)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					v, err := c.Fetch(context.Background(), "a")
+					if err == nil && v != "value-a" {
+						err = errors.New("wrong value " + v)
+					}
+					errs <- err
+				}()
+			}
+			// Let every caller miss before the load finishes
+			for c.Stats().Misses < callers {
+				time.Sleep(time.Millisecond)
+			}
+			close(release)
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Error(err)
+				}
+			}
+
+			if calls.Load() != 1 {
+				t.Errorf("loader called %d times, want 1", calls.Load())
+			}
+			s := c.Stats()
+			if s.Misses != callers || s.Hits != 0 || s.Loads != 1 || s.Shared != callers-1 {
+				t.Errorf("stats = %+v, want %d misses, 1 load and %d shared", s, callers, callers-1)
+			}
+		})
+	}
+}
+
+func TestStampedeLockAcrossCaches(t *testing.T) {
+	rdb := newClient(t)
+	var calls atomic.Int64
+	release := make(chan struct{})
+	opts := Options{Prefix: "t:", Stampede: StampedeLock, LockTTL: 5 * time.Second}
+
+	// Two caches on one Redis stand in for two processes
+	winner := New(rdb, countingLoader(&calls, release), opts)
+	waiter := New(rdb, countingLoader(&calls, nil), opts)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := winner.Fetch(context.Background(), "a")
+		done <- err
+	}()
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	result := make(chan string, 1)
+	go func() {
+		v, _ := waiter.Fetch(context.Background(), "a")
+		result <- v
+	}()
+	for waiter.Stats().LockWaits == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if v := <-result; v != "value-a" {
+		t.Errorf("waiter got %q, want the winner's value", v)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("loader called %d times, want 1", calls.Load())
+	}
+	if s := winner.Stats(); s.Misses != 1 || s.Hits != 0 || s.Loads != 1 {
+		t.Errorf("winner stats = %+v, want 1 miss and 1 load", s)
+	}
+	if s := waiter.Stats(); s.Misses != 1 || s.Hits != 0 || s.Loads != 0 || s.LockWaits != 1 {
+		t.Errorf("waiter stats = %+v, want 1 miss and 1 lock wait", s)
+	}
+	if n, _ := rdb.Exists(context.Background(), "t:a:lock").Result(); n != 0 {
+		t.Error("recompute lock left behind")
+	}
+}
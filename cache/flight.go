@@ -0,0 +1,44 @@
+package cache
+
+import "sync"
+
+// flight collapses concurrent calls for the same key into one, in the
+// manner of golang.org/x/sync/singleflight
+type flight[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+type call[T any] struct {
+	wg  sync.WaitGroup
+	val T
+	err error
+}
+
+// do runs fn once for key at a time. Callers arriving while it runs wait
+// for its result, and shared reports whether this caller got a result
+// produced for someone else.
+func (f *flight[T]) do(key string, fn func() (T, error)) (v T, err error, shared bool) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]*call[T])
+	}
+	if c, ok := f.calls[key]; ok {
+		f.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err, true
+	}
+	c := &call[T]{}
+	c.wg.Add(1)
+	f.calls[key] = c
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.calls, key)
+		f.mu.Unlock()
+		c.wg.Done()
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err, false
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	mathrand "math/rand"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Stampede is a strategy against many clients recomputing an expired key
+// at the same moment
+type Stampede int
+
+const (
+	// StampedeNone lets every client that misses recompute, deduplicated
+	// only within one process
+	StampedeNone Stampede = iota
+	// StampedeXFetch stores how long each value took to compute and lets
+	// callers refresh it before expiry with a probability that rises as
+	// expiry nears and with the recompute time (Vattani et al., "Optimal
+	// Probabilistic Cache Stampede Prevention"). No coordination is needed.
+	StampedeXFetch
+	// StampedeLock has the first caller after a miss take a lock in Redis
+	// and recompute, while other callers poll for its result
+	StampedeLock
+)
+
+func (s Stampede) String() string {
+	switch s {
+	case StampedeXFetch:
+		return "xfetch"
+	case StampedeLock:
+		return "lock"
+	}
+	return "none"
+}
+
+// lockPoll is how often callers waiting on a recompute lock check the cache
+const lockPoll = 10 * time.Millisecond
+
+// releaseLock deletes the recompute lock only if it still holds our token
+var releaseLock = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// refreshEarly is the XFetch test: refresh once
+// delta * beta * -ln(rand) reaches the remaining TTL
+func (c *Cache[T]) refreshEarly(e entry[T]) bool {
+	if e.delta <= 0 || e.ttl <= 0 {
+		return false
+	}
+	// 1 - Float64() is in (0, 1], so the logarithm is finite
+	gap := float64(e.delta) * c.opts.Beta * -math.Log(1-mathrand.Float64())
+	return time.Duration(gap) >= e.ttl
+}
+
+// loadLocked recomputes key under a Redis lock so that only one caller
+// across all processes runs the loader. The others wait up to LockTTL for
+// the value to appear, then load it themselves.
+func (c *Cache[T]) loadLocked(ctx context.Context, key string) (T, error) {
+	v, err, shared := c.flight.do(key, func() (T, error) {
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			var zero T
+			return zero, err
+		}
+		lockKey := c.opts.Prefix + key + ":lock"
+		ok, err := c.rdb.SetNX(ctx, lockKey, hex.EncodeToString(token), c.opts.LockTTL).Result()
+		if err != nil {
+			return c.loadUnshared(ctx, key)
+		}
+		if ok {
+			defer releaseLock.Run(context.WithoutCancel(ctx), c.rdb, []string{lockKey}, hex.EncodeToString(token))
+			// The previous holder may have filled the cache just before we
+			// got the lock
+			if e, err := c.read(ctx, key, false); err == nil || err == ErrNotFound {
+				return e.value, err
+			}
+			return c.loadUnshared(ctx, key)
+		}
+
+		c.lockWaits.Add(1)
+		deadline := time.Now().Add(c.opts.LockTTL)
+		for time.Now().Before(deadline) {
+			select {
+			case <-ctx.Done():
+				var zero T
+				return zero, ctx.Err()
+			case <-time.After(lockPoll):
+			}
+			e, err := c.read(ctx, key, false)
+			if err == nil || err == ErrNotFound {
+				return e.value, err
+			}
+			var outage *OutageError
+			if errors.As(err, &outage) {
+				break
+			}
+		}
+		return c.loadUnshared(ctx, key)
+	})
+	if shared {
+		c.shared.Add(1)
+	}
+	return v, err
+}
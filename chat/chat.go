@@ -0,0 +1,396 @@
+// Package chat is a multi-room chat over Redis. Each room is a pub/sub
+// channel for live messages and a capped list holding the latest ones for
+// history. Presence is a set of nicknames per room, and each nickname is
+// claimed by a session with a key that its heartbeat keeps alive, so users
+// whose process died drop out of the rooms once the key expires.
+package chat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNickTaken is returned by Connect when another live session uses the
+// nickname
+var ErrNickTaken = errors.New("chat: nickname in use")
+
+// ErrNotJoined is returned when sending to a room the session has not joined
+var ErrNotJoined = errors.New("chat: not in that room")
+
+// ErrNickLost is returned by Join and Send once another session has taken
+// the nickname, after this one's claim lapsed
+var ErrNickLost = errors.New("chat: nickname taken by another session")
+
+// Message kinds
+const (
+	KindText  = "text"
+	KindJoin  = "join"
+	KindLeave = "leave"
+	// KindSystem messages come from the session itself, never from Redis
+	KindSystem = "system"
+)
+
+// Message is one chat event in a room
+type Message struct {
+	Room string    `json:"room"`
+	Nick string    `json:"nick"`
+	Kind string    `json:"kind"`
+	Text string    `json:"text,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// heartbeatScript extends the nickname claim while it holds our token and
+// returns 1, or takes it back if it expired in the meantime, e.g. during a
+// network blip, and returns 2
+var heartbeatScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner == ARGV[1] then
+  redis.call('PEXPIRE', KEYS[1], ARGV[2])
+  return 1
+elseif not owner then
+  redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+  return 2
+end
+return 0
+`)
+
+// releaseScript deletes the nickname claim only if it holds our token
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Options configures a Session
+type Options struct {
+	// Prefix is prepended to every key and channel, it defaults to
+	// "termchat:"
+	Prefix string
+	// History is how many messages each room keeps, it defaults to 50
+	History int
+	// Heartbeat is how often the session proves it is alive, it defaults to
+	// 2s. A nickname is released after three missed heartbeats.
+	Heartbeat time.Duration
+}
+
+// Session is one user connected under a nickname
+type Session struct {
+	rdb   *redis.Client
+	opts  Options
+	nick  string
+	token string
+
+	pubsub   *redis.PubSub
+	messages chan Message
+
+	mu    sync.Mutex
+	rooms map[string]bool
+	// lost is set once the nickname belongs to another session
+	lost bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// Connect claims nick and starts the session's heartbeat, filling in
+// defaults for unset options
+func Connect(ctx context.Context, rdb *redis.Client, nick string, opts Options) (*Session, error) {
+	if opts.Prefix == "" {
+		opts.Prefix = "termchat:"
+	}
+	if opts.History <= 0 {
+		opts.History = 50
+	}
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = 2 * time.Second
+	}
+	nick = strings.TrimSpace(nick)
+	if nick == "" || strings.ContainsAny(nick, " \t") {
+		return nil, errors.New("chat: nickname must be one word")
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	s := &Session{
+		rdb:      rdb,
+		opts:     opts,
+		nick:     nick,
+		token:    hex.EncodeToString(buf),
+		messages: make(chan Message, 100),
+		rooms:    make(map[string]bool),
+		stop:     make(chan struct{}),
+	}
+	ok, err := rdb.SetNX(ctx, s.userKey(nick), s.token, s.presenceTTL()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNickTaken
+	}
+
+	// Rooms are added to the subscription as they are joined. The
+	// session's own channel keeps it from ever being empty, which a pub/sub
+	// connection cannot be.
+	s.pubsub = rdb.Subscribe(ctx, s.channel("@"+nick))
+	s.wg.Add(2)
+	go s.receive()
+	go s.heartbeat()
+	return s, nil
+}
+
+// Nick returns the session's nickname
+func (s *Session) Nick() string { return s.nick }
+
+// Messages delivers messages from joined rooms, including the session's
+// own. It is closed by Close.
+func (s *Session) Messages() <-chan Message { return s.messages }
+
+// Rooms returns the joined rooms in alphabetical order
+func (s *Session) Rooms() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rooms := make([]string, 0, len(s.rooms))
+	for room := range s.rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Strings(rooms)
+	return rooms
+}
+
+// Join subscribes to room, adds the session to its presence set and
+// announces it
+func (s *Session) Join(ctx context.Context, room string) error {
+	s.mu.Lock()
+	lost := s.lost
+	s.mu.Unlock()
+	if lost {
+		return ErrNickLost
+	}
+	if err := s.pubsub.Subscribe(ctx, s.channel(room)); err != nil {
+		return err
+	}
+	if err := s.rdb.SAdd(ctx, s.presenceKey(room), s.nick).Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	lost = s.lost
+	if !lost {
+		s.rooms[room] = true
+	}
+	s.mu.Unlock()
+	if lost {
+		s.pubsub.Unsubscribe(ctx, s.channel(room))
+		return ErrNickLost
+	}
+	return s.publish(ctx, Message{Room: room, Nick: s.nick, Kind: KindJoin})
+}
+
+// Leave announces the departure, then unsubscribes from room and removes
+// the session from its presence set
+func (s *Session) Leave(ctx context.Context, room string) error {
+	s.mu.Lock()
+	joined := s.rooms[room]
+	delete(s.rooms, room)
+	s.mu.Unlock()
+	if !joined {
+		return ErrNotJoined
+	}
+	if err := s.publish(ctx, Message{Room: room, Nick: s.nick, Kind: KindLeave}); err != nil {
+		return err
+	}
+	if err := s.rdb.SRem(ctx, s.presenceKey(room), s.nick).Err(); err != nil {
+		return err
+	}
+	return s.pubsub.Unsubscribe(ctx, s.channel(room))
+}
+
+// Send publishes text to a joined room
+func (s *Session) Send(ctx context.Context, room, text string) error {
+	s.mu.Lock()
+	joined, lost := s.rooms[room], s.lost
+	s.mu.Unlock()
+	if lost {
+		return ErrNickLost
+	}
+	if !joined {
+		return ErrNotJoined
+	}
+	return s.publish(ctx, Message{Room: room, Nick: s.nick, Kind: KindText, Text: text})
+}
+
+// Who returns the nicknames present in room. Members whose session stopped
+// heartbeating are removed from the set on the way.
+func (s *Session) Who(ctx context.Context, room string) ([]string, error) {
+	members, err := s.rdb.SMembers(ctx, s.presenceKey(room)).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	keys := make([]string, len(members))
+	for i, nick := range members {
+		keys[i] = s.userKey(nick)
+	}
+	alive, err := s.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var present, stale []string
+	for i, nick := range members {
+		if alive[i] == nil {
+			stale = append(stale, nick)
+		} else {
+			present = append(present, nick)
+		}
+	}
+	if len(stale) > 0 {
+		s.rdb.SRem(ctx, s.presenceKey(room), stale)
+	}
+	sort.Strings(present)
+	return present, nil
+}
+
+// History returns up to n of the latest messages in room, oldest first
+func (s *Session) History(ctx context.Context, room string, n int) ([]Message, error) {
+	if n <= 0 {
+		return nil, errors.New("chat: history length must be positive")
+	}
+	raw, err := s.rdb.LRange(ctx, s.historyKey(room), 0, int64(n)-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]Message, 0, len(raw))
+	for i := len(raw) - 1; i >= 0; i-- {
+		var m Message
+		if json.Unmarshal([]byte(raw[i]), &m) == nil {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+// Close leaves every room, releases the nickname and stops the session
+func (s *Session) Close(ctx context.Context) error {
+	var firstErr error
+	for _, room := range s.Rooms() {
+		if err := s.Leave(ctx, room); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	close(s.stop)
+	s.pubsub.Close()
+	s.wg.Wait()
+	close(s.messages)
+	if err := releaseScript.Run(ctx, s.rdb, []string{s.userKey(s.nick)}, s.token).Err(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// publish sends m live and appends it to the room's capped history in one
+// transaction
+func (s *Session) publish(ctx context.Context, m Message) error {
+	m.Time = time.Now()
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Publish(ctx, s.channel(m.Room), data)
+		if m.Kind == KindText {
+			pipe.LPush(ctx, s.historyKey(m.Room), data)
+			pipe.LTrim(ctx, s.historyKey(m.Room), 0, int64(s.opts.History)-1)
+		}
+		return nil
+	})
+	return err
+}
+
+// receive decodes messages from the subscription until it is closed
+func (s *Session) receive() {
+	defer s.wg.Done()
+	for msg := range s.pubsub.Channel() {
+		var m Message
+		if err := json.Unmarshal([]byte(msg.Payload), &m); err != nil {
+			continue
+		}
+		s.deliver(m)
+	}
+}
+
+// deliver hands m to the reader, dropping it if the reader fell far behind
+func (s *Session) deliver(m Message) {
+	select {
+	case s.messages <- m:
+	default:
+	}
+}
+
+// heartbeat keeps the nickname claimed and the session present in its rooms
+func (s *Session) heartbeat() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.opts.Heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), s.opts.Heartbeat)
+		held, err := heartbeatScript.Run(ctx, s.rdb, []string{s.userKey(s.nick)}, s.token, s.presenceTTL().Milliseconds()).Int()
+		if err == nil && held == 2 {
+			// Another user's Who may have pruned us while the claim had lapsed
+			for _, room := range s.Rooms() {
+				s.rdb.SAdd(ctx, s.presenceKey(room), s.nick)
+			}
+		}
+		if err == nil && held == 0 {
+			s.lose(ctx)
+			cancel()
+			s.deliver(Message{Nick: s.nick, Kind: KindSystem, Text: "nickname was taken by another session", Time: time.Now()})
+			return
+		}
+		cancel()
+	}
+}
+
+// lose marks the session dead after another session took the nickname and
+// stops listening to its rooms. The presence entries are left alone since
+// they now stand for the new owner.
+func (s *Session) lose(ctx context.Context) {
+	s.mu.Lock()
+	s.lost = true
+	channels := make([]string, 0, len(s.rooms))
+	for room := range s.rooms {
+		channels = append(channels, s.channel(room))
+	}
+	s.rooms = make(map[string]bool)
+	s.mu.Unlock()
+
+	// Unsubscribe without channels would drop the session's own channel too
+	if len(channels) > 0 {
+		s.pubsub.Unsubscribe(ctx, channels...)
+	}
+}
+
+func (s *Session) presenceTTL() time.Duration { return 3 * s.opts.Heartbeat }
+
+func (s *Session) userKey(nick string) string { return s.opts.Prefix + "user:" + nick }
+
+func (s *Session) presenceKey(room string) string { return s.opts.Prefix + "presence:" + room }
+
+func (s *Session) historyKey(room string) string { return s.opts.Prefix + "history:" + room }
+
+func (s *Session) channel(room string) string { return s.opts.Prefix + "room:" + room }
@@ -0,0 +1,182 @@
+package compat
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// recorder is a go-redis hook that tracks every command a client sends,
+// whether the server knew it and the shape of the replies it returned
+type recorder struct {
+	mu       sync.Mutex
+	commands map[string]*CommandResult
+}
+
+func newRecorder() *recorder {
+	return &recorder{commands: make(map[string]*CommandResult)}
+}
+
+func (r *recorder) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (r *recorder) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		// go-redis sets the error on cmd only after the hooks return
+		err := next(ctx, cmd)
+		r.observe(cmd, err)
+		return err
+	}
+}
+
+func (r *recorder) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			r.observe(cmd, cmd.Err())
+		}
+		return err
+	}
+}
+
+func (r *recorder) observe(cmd redis.Cmder, err error) {
+	name := strings.ToUpper(cmd.Name())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := r.result(name)
+	res.Calls++
+	if err != nil && err != redis.Nil {
+		// Dial failures, timeouts and cancellations say nothing about the
+		// server, only error replies do
+		var reply redis.Error
+		if !errors.As(err, &reply) {
+			return
+		}
+		if isUnknownCommand(err) {
+			res.Supported = false
+		}
+		res.Errors = appendUnique(res.Errors, err.Error())
+		return
+	}
+	res.Shapes = appendUnique(res.Shapes, replyShape(cmd, err))
+}
+
+// addCheck counts an example expectation against command. Labels like
+// "SET NX PX" or "PUBSUB CHANNELS" count against the command they name.
+func (r *recorder) addCheck(command string, passed bool) {
+	if fields := strings.Fields(command); len(fields) > 0 {
+		command = strings.ToUpper(fields[0])
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := r.result(command)
+	if passed {
+		res.ChecksPassed++
+	} else {
+		res.ChecksFailed++
+	}
+}
+
+// result returns the entry for name, creating it if needed. Callers must hold mu.
+func (r *recorder) result(name string) *CommandResult {
+	res, ok := r.commands[name]
+	if !ok {
+		res = &CommandResult{Command: name, Supported: true}
+		r.commands[name] = res
+	}
+	return res
+}
+
+// snapshot returns the recorded commands sorted by name
+func (r *recorder) snapshot() []*CommandResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]*CommandResult, 0, len(r.commands))
+	for _, res := range r.commands {
+		sort.Strings(res.Shapes)
+		sort.Strings(res.Errors)
+		out = append(out, res)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Command < out[j].Command })
+	return out
+}
+
+func isUnknownCommand(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unknown command") ||
+		strings.Contains(msg, "not supported") ||
+		strings.Contains(msg, "unsupported")
+}
+
+// replyShape describes the parsed reply of cmd without its contents,
+// e.g. "array[3] of string" or "map[5]"
+func replyShape(cmd redis.Cmder, err error) string {
+	if err == redis.Nil {
+		return "nil"
+	}
+	val := reflect.ValueOf(cmd).MethodByName("Val")
+	if !val.IsValid() {
+		return "unknown"
+	}
+	out := val.Call(nil)
+	if len(out) == 0 {
+		return "unknown"
+	}
+	return valueShape(out[0].Interface())
+}
+
+func valueShape(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return "string"
+	case int64, int:
+		return "integer"
+	case float64:
+		return "double"
+	case bool:
+		return "boolean"
+	case time.Duration:
+		return "integer"
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.Len() == 0 {
+			return "array[0]"
+		}
+		return "array[" + strconv.Itoa(rv.Len()) + "] of " + valueShape(rv.Index(0).Interface())
+	case reflect.Map:
+		return "map[" + strconv.Itoa(rv.Len()) + "]"
+	case reflect.Struct:
+		return strings.ToLower(rv.Type().Name())
+	}
+	return rv.Kind().String()
+}
+
+func appendUnique(list []string, v string) []string {
+	for _, s := range list {
+		if s == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
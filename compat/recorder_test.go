@@ -0,0 +1,92 @@
+package compat
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestValueShape(t *testing.T) {
+	for _, tc := range []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "nil"},
+		{"x", "string"},
+		{int64(3), "integer"},
+		{1.5, "double"},
+		{true, "boolean"},
+		{time.Second, "integer"},
+		{[]string{}, "array[0]"},
+		{[]string{"a", "b"}, "array[2] of string"},
+		{[]interface{}{int64(1), "a"}, "array[2] of integer"},
+		{map[string]string{"a": "1"}, "map[1]"},
+		{redis.Z{Score: 1, Member: "a"}, "z"},
+	} {
+		if got := valueShape(tc.value); got != tc.want {
+			t.Errorf("valueShape(%#v) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer rdb.Close()
+	rec := newRecorder()
+	rdb.AddHook(rec)
+
+	ctx := context.Background()
+	rdb.Set(ctx, "k", "v", 0)
+	rdb.Get(ctx, "k")
+	rdb.Get(ctx, "missing")
+	rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, "h", "f", "1")
+		pipe.HGetAll(ctx, "h")
+		return nil
+	})
+	rdb.Do(ctx, "NOSUCHCOMMAND")
+
+	// A client-side failure is not the server's doing
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	rdb.Get(cancelled, "k")
+
+	rec.addCheck("GET", true)
+	rec.addCheck("SET NX PX", false)
+
+	got := make(map[string]*CommandResult)
+	for _, c := range rec.snapshot() {
+		got[c.Command] = c
+	}
+
+	get := got["GET"]
+	if get == nil || get.Calls != 3 || !get.Supported {
+		t.Fatalf("GET = %+v, want 3 supported calls", get)
+	}
+	if len(get.Shapes) != 2 || get.Shapes[0] != "nil" || get.Shapes[1] != "string" {
+		t.Errorf("GET shapes = %v, want [nil string]", get.Shapes)
+	}
+	if len(get.Errors) != 0 {
+		t.Errorf("GET errors = %v, want none for a cancelled context", get.Errors)
+	}
+	if get.ChecksPassed != 1 {
+		t.Errorf("GET checks passed = %d, want 1", get.ChecksPassed)
+	}
+
+	if set := got["SET"]; set == nil || set.ChecksFailed != 1 {
+		t.Errorf("SET = %+v, want the SET NX PX check counted against it", set)
+	}
+	if _, ok := got["SET NX PX"]; ok {
+		t.Error("check label recorded as a command of its own")
+	}
+	if h := got["HGETALL"]; h == nil || len(h.Shapes) != 1 || h.Shapes[0] != "map[1]" {
+		t.Errorf("pipelined HGETALL = %+v, want shape map[1]", h)
+	}
+	if unknown := got["NOSUCHCOMMAND"]; unknown == nil || unknown.Supported {
+		t.Errorf("NOSUCHCOMMAND = %+v, want unsupported", unknown)
+	}
+}
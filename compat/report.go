@@ -0,0 +1,273 @@
+// Package compat runs the example suite against several Redis-compatible
+// servers and reports, per command, which ones support it, whether it
+// produced the expected result and how the reply shapes differ.
+//
+// Two local servers are enough to try it out, e.g.
+//
+//	go run . compat localhost:6379 localhost:6380
+package compat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"redis-playground/config"
+	"redis-playground/examples"
+)
+
+// CommandResult is how one server handled one command
+type CommandResult struct {
+	Command      string   `json:"command"`
+	Calls        int      `json:"calls"`
+	Supported    bool     `json:"supported"`
+	ChecksPassed int      `json:"checks_passed"`
+	ChecksFailed int      `json:"checks_failed"`
+	Shapes       []string `json:"reply_shapes,omitempty"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// ServerReport is the outcome of running the suite against one endpoint
+type ServerReport struct {
+	Addr     string           `json:"addr"`
+	Version  string           `json:"version,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Commands []*CommandResult `json:"commands"`
+	Failures []examples.Check `json:"failures,omitempty"`
+}
+
+// ShapeDifference lists the reply shapes each server returned for a
+// command whose replies did not agree
+type ShapeDifference struct {
+	Command string              `json:"command"`
+	Shapes  map[string][]string `json:"shapes"`
+}
+
+// Report is the compatibility matrix for all servers
+type Report struct {
+	Servers     []ServerReport    `json:"servers"`
+	Differences []ShapeDifference `json:"differences,omitempty"`
+}
+
+// Run runs the verifiable examples against every address in turn
+func Run(ctx context.Context, addrs []string) Report {
+	var report Report
+	for _, addr := range addrs {
+		fmt.Printf("\n==== %s ====\n", addr)
+		report.Servers = append(report.Servers, runServer(ctx, addr))
+	}
+	report.Differences = shapeDifferences(report.Servers)
+	return report
+}
+
+func runServer(ctx context.Context, addr string) ServerReport {
+	server := ServerReport{Addr: addr}
+
+	rdb := config.NewClientAt(addr)
+	defer rdb.Close()
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		server.Error = err.Error()
+		return server
+	}
+	server.Version = serverVersion(rdb.Info(ctx, "server").Val())
+
+	// The recorder also goes on the clients and subscriptions the examples
+	// open themselves
+	rec := newRecorder()
+	rdb.AddHook(rec)
+	examples.SetClientHooks(rec)
+	defer examples.SetClientHooks()
+	checks := examples.Verify(rdb)
+
+	// Attribute each check to the command it exercised
+	for _, c := range checks {
+		if c.Command == "" {
+			server.Failures = append(server.Failures, c)
+			continue
+		}
+		rec.addCheck(c.Command, c.Passed)
+		if !c.Passed {
+			server.Failures = append(server.Failures, c)
+		}
+	}
+
+	server.Commands = rec.snapshot()
+	return server
+}
+
+// serverVersion picks the product and version out of an INFO server reply
+func serverVersion(info string) string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if ok {
+			fields[key] = value
+		}
+	}
+
+	for _, product := range []string{"dragonfly", "valkey", "keydb"} {
+		if v, ok := fields[product+"_version"]; ok {
+			return product + " " + v
+		}
+	}
+	if name, ok := fields["server_name"]; ok {
+		return name + " " + fields["redis_version"]
+	}
+	return "redis " + fields["redis_version"]
+}
+
+func shapeDifferences(servers []ServerReport) []ShapeDifference {
+	byCommand := make(map[string]map[string][]string)
+	for _, s := range servers {
+		for _, c := range s.Commands {
+			if !c.Supported || len(c.Shapes) == 0 {
+				continue
+			}
+			if byCommand[c.Command] == nil {
+				byCommand[c.Command] = make(map[string][]string)
+			}
+			byCommand[c.Command][s.Addr] = c.Shapes
+		}
+	}
+
+	var diffs []ShapeDifference
+	for command, shapes := range byCommand {
+		if len(shapes) < 2 || allEqual(shapes) {
+			continue
+		}
+		diffs = append(diffs, ShapeDifference{Command: command, Shapes: shapes})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Command < diffs[j].Command })
+	return diffs
+}
+
+func allEqual(shapes map[string][]string) bool {
+	var first string
+	seen := false
+	for _, s := range shapes {
+		joined := strings.Join(s, "|")
+		if !seen {
+			first, seen = joined, true
+		} else if joined != first {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteJSON writes the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as a Markdown command matrix followed by
+// the failed checks and reply-shape differences
+func (r Report) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("# Redis compatibility report\n\n")
+	b.WriteString("| Server | Version | Status |\n|---|---|---|\n")
+	for _, s := range r.Servers {
+		status := "ok"
+		if s.Error != "" {
+			status = "unreachable: " + s.Error
+		} else if len(s.Failures) > 0 {
+			status = fmt.Sprintf("%d failed checks", len(s.Failures))
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", s.Addr, s.Version, status)
+	}
+
+	b.WriteString("\n## Commands\n\n")
+	b.WriteString("✓ supported and matched, ✗ failed checks, — not supported, blank: not reached\n\n")
+	b.WriteString("| Command |")
+	for _, s := range r.Servers {
+		fmt.Fprintf(&b, " %s |", s.Addr)
+	}
+	b.WriteString("\n|---|")
+	for range r.Servers {
+		b.WriteString("---|")
+	}
+	b.WriteString("\n")
+
+	for _, command := range r.commandNames() {
+		fmt.Fprintf(&b, "| %s |", command)
+		for _, s := range r.Servers {
+			fmt.Fprintf(&b, " %s |", cell(s.find(command)))
+		}
+		b.WriteString("\n")
+	}
+
+	for _, s := range r.Servers {
+		if len(s.Failures) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## Failed checks on %s\n\n", s.Addr)
+		b.WriteString("| Example | Command | Step | Expected | Actual |\n|---|---|---|---|---|\n")
+		for _, c := range s.Failures {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+				c.Example, c.Command, c.Step, escape(c.Expected), escape(c.Actual))
+		}
+	}
+
+	if len(r.Differences) > 0 {
+		b.WriteString("\n## Reply-shape differences\n\n")
+		for _, d := range r.Differences {
+			fmt.Fprintf(&b, "- **%s**\n", d.Command)
+			for _, s := range r.Servers {
+				if shapes, ok := d.Shapes[s.Addr]; ok {
+					fmt.Fprintf(&b, "  - %s: %s\n", s.Addr, strings.Join(shapes, ", "))
+				}
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (r Report) commandNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range r.Servers {
+		for _, c := range s.Commands {
+			if !seen[c.Command] {
+				seen[c.Command] = true
+				names = append(names, c.Command)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s ServerReport) find(command string) *CommandResult {
+	for _, c := range s.Commands {
+		if c.Command == command {
+			return c
+		}
+	}
+	return nil
+}
+
+func cell(c *CommandResult) string {
+	switch {
+	case c == nil:
+		return ""
+	case !c.Supported:
+		return "—"
+	case c.ChecksFailed > 0:
+		return fmt.Sprintf("✗ %d/%d", c.ChecksPassed, c.ChecksPassed+c.ChecksFailed)
+	default:
+		return "✓"
+	}
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -0,0 +1,215 @@
+package compat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"redis-playground/examples"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+)
+
+func TestShapeDifferences(t *testing.T) {
+	servers := []ServerReport{
+		{Addr: "a:6379", Commands: []*CommandResult{
+			{Command: "GET", Supported: true, Shapes: []string{"nil", "string"}},
+			{Command: "HGETALL", Supported: true, Shapes: []string{"map[2]"}},
+			{Command: "XINFO", Supported: true, Shapes: []string{"map[10]"}},
+			{Command: "ZRANGE", Supported: true, Shapes: []string{"array[3] of string"}},
+		}},
+		{Addr: "b:6379", Commands: []*CommandResult{
+			{Command: "GET", Supported: true, Shapes: []string{"nil", "string"}},
+			{Command: "HGETALL", Supported: true, Shapes: []string{"array[4] of string"}},
+			{Command: "XINFO", Supported: false, Errors: []string{"ERR unknown command 'XINFO'"}},
+		}},
+	}
+
+	diffs := shapeDifferences(servers)
+	if len(diffs) != 1 {
+		t.Fatalf("got %d differences, want 1: %+v", len(diffs), diffs)
+	}
+	d := diffs[0]
+	if d.Command != "HGETALL" {
+		t.Errorf("difference for %s, want HGETALL", d.Command)
+	}
+	if got := strings.Join(d.Shapes["a:6379"], ","); got != "map[2]" {
+		t.Errorf("a:6379 shapes = %s", got)
+	}
+	if got := strings.Join(d.Shapes["b:6379"], ","); got != "array[4] of string" {
+		t.Errorf("b:6379 shapes = %s", got)
+	}
+}
+
+func TestShapeDifferencesOrder(t *testing.T) {
+	servers := []ServerReport{
+		{Addr: "a", Commands: []*CommandResult{
+			{Command: "ZADD", Supported: true, Shapes: []string{"integer"}},
+			{Command: "INCRBYFLOAT", Supported: true, Shapes: []string{"double"}},
+		}},
+		{Addr: "b", Commands: []*CommandResult{
+			{Command: "ZADD", Supported: true, Shapes: []string{"string"}},
+			{Command: "INCRBYFLOAT", Supported: true, Shapes: []string{"string"}},
+		}},
+	}
+	diffs := shapeDifferences(servers)
+	if len(diffs) != 2 || diffs[0].Command != "INCRBYFLOAT" || diffs[1].Command != "ZADD" {
+		t.Fatalf("differences not sorted by command: %+v", diffs)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	report := Report{
+		Servers: []ServerReport{
+			{
+				Addr:    "a:6379",
+				Version: "redis 7.2.4",
+				Commands: []*CommandResult{
+					{Command: "GET", Calls: 3, Supported: true, ChecksPassed: 2, Shapes: []string{"string"}},
+					{Command: "HGETALL", Calls: 1, Supported: true, ChecksPassed: 1, Shapes: []string{"map[2]"}},
+				},
+			},
+			{
+				Addr:    "b:6379",
+				Version: "dragonfly 1.15.0",
+				Commands: []*CommandResult{
+					{Command: "GET", Calls: 3, Supported: true, ChecksPassed: 1, ChecksFailed: 1, Shapes: []string{"string"}},
+					{Command: "HGETALL", Calls: 1, Supported: true, ChecksPassed: 1, Shapes: []string{"array[4] of string"}},
+					{Command: "OBJECT", Calls: 1, Supported: false},
+				},
+				Failures: []examples.Check{
+					{Example: "strings", Command: "GET", Step: "value", Expected: "a|b", Actual: "a"},
+				},
+			},
+			{Addr: "c:6379", Error: "connection refused"},
+		},
+	}
+	report.Differences = shapeDifferences(report.Servers)
+
+	var b strings.Builder
+	if err := report.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	md := b.String()
+
+	for _, want := range []string{
+		"| a:6379 | redis 7.2.4 | ok |",
+		"| b:6379 | dragonfly 1.15.0 | 1 failed checks |",
+		"| c:6379 |  | unreachable: connection refused |",
+		"| Command | a:6379 | b:6379 | c:6379 |",
+		"| GET | ✓ | ✗ 1/2 |  |",
+		"| HGETALL | ✓ | ✓ |  |",
+		"| OBJECT |  | — |  |",
+		"## Failed checks on b:6379",
+		"| strings | GET | value | a\\|b | a |",
+		"- **HGETALL**",
+		"  - a:6379: map[2]",
+		"  - b:6379: array[4] of string",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Failed checks on a:6379") {
+		t.Error("markdown lists failed checks for a server without failures")
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	for _, tc := range []struct {
+		info string
+		want string
+	}{
+		{"# Server\r\nredis_version:7.2.4\r\n", "redis 7.2.4"},
+		{"redis_version:7.2.4\r\nserver_name:valkey\r\nvalkey_version:8.0.1\r\n", "valkey 8.0.1"},
+		{"redis_version:6.2.0\r\ndragonfly_version:df-v1.15.0\r\n", "dragonfly df-v1.15.0"},
+	} {
+		if got := serverVersion(tc.info); got != tc.want {
+			t.Errorf("serverVersion(%q) = %q, want %q", tc.info, got, tc.want)
+		}
+	}
+}
+
+func TestRunTwoServers(t *testing.T) {
+	// Two examples are enough to cover the report, the full suite takes long
+	defer func(all []examples.Example) { examples.Verifiable = all }(examples.Verifiable)
+	examples.Verifiable = []examples.Example{
+		{Name: "strings", Run: examples.RunStringExamples},
+		{Name: "hashes", Run: examples.RunHashesExamples},
+	}
+	examples.SetFastMode(true)
+	defer examples.SetFastMode(false)
+
+	a := miniredis.RunT(t)
+	// b lacks HEXISTS and returns the wrong email, so the hashes example
+	// fails a check there and stops before the later hash commands
+	b := miniredis.RunT(t)
+	b.Server().SetPreHook(func(c *server.Peer, cmd string, args ...string) bool {
+		switch {
+		case cmd == "HEXISTS":
+			c.WriteError("ERR unknown command 'HEXISTS', with args beginning with: ")
+			return true
+		case cmd == "HGET" && len(args) == 2 && args[1] == "email":
+			c.WriteBulk("someone@else.example")
+			return true
+		}
+		return false
+	})
+
+	report := Run(context.Background(), []string{a.Addr(), b.Addr()})
+	if len(report.Servers) != 2 {
+		t.Fatalf("got %d server reports, want 2", len(report.Servers))
+	}
+	for _, s := range report.Servers {
+		if s.Error != "" {
+			t.Fatalf("%s: %s", s.Addr, s.Error)
+		}
+	}
+	if n := len(report.Servers[0].Failures); n != 0 {
+		t.Errorf("%s has %d failed checks, want none: %+v", a.Addr(), n, report.Servers[0].Failures)
+	}
+
+	var md strings.Builder
+	if err := report.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| " + a.Addr() + " | ",
+		"| Command | " + a.Addr() + " | " + b.Addr() + " |",
+		"| SET | ✓ | ✓ |",
+		"| HGET | ✓ | ✗ 1/2 |",
+		"| HEXISTS | ✓ | — |",
+		"| HLEN | ✓ |  |",
+		"## Failed checks on " + b.Addr(),
+		"| hashes | HGET | email field |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md.String())
+		}
+	}
+	if strings.Contains(md.String(), "## Failed checks on "+a.Addr()) {
+		t.Error("markdown lists failed checks for the server without failures")
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("report JSON does not decode: %v", err)
+	}
+	if len(decoded.Servers) != 2 || decoded.Servers[1].Addr != b.Addr() {
+		t.Fatalf("decoded servers = %+v", decoded.Servers)
+	}
+	hexists := decoded.Servers[1].find("HEXISTS")
+	if hexists == nil || hexists.Supported || len(hexists.Errors) == 0 {
+		t.Errorf("HEXISTS on %s = %+v, want unsupported with its error", b.Addr(), hexists)
+	}
+	if len(decoded.Servers[1].Failures) != 1 || decoded.Servers[1].Failures[0].Command != "HGET" {
+		t.Errorf("failures on %s = %+v, want the HGET email check", b.Addr(), decoded.Servers[1].Failures)
+	}
+}
@@ -32,6 +32,19 @@ func InitRedis() *redis.Client {
 	return client
 }
 
+// NewClientAt returns a Redis client for addr, using the password and DB
+// from the environment
+func NewClientAt(addr string) *redis.Client {
+	// A missing .env file is fine here, defaults apply
+	_ = godotenv.Load()
+
+	return redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: getEnv("REDIS_PASSWORD", ""),
+		DB:       getEnvAsInt("REDIS_DB", 0),
+	})
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
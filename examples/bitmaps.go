@@ -0,0 +1,205 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunBitmapExamples demonstrates bitmaps and BITFIELD for activity tracking
+func RunBitmapExamples(rdb *redis.Client) {
+	fmt.Println("\n Bitmap Operations")
+	fmt.Println("====================")
+
+	ctx := context.Background()
+
+	// Every step keeps the same data in a Set of "user:<id>" members, like
+	// the online users example, so the memory footprints can be compared
+	const users = 1000
+	days := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	var bitmapKeys, setKeys []string
+
+	// SETBIT / GETBIT - One bit per user ID per day
+	fmt.Println("1. Tracking daily active users with SETBIT and GETBIT:")
+	rng := rand.New(rand.NewSource(34))
+	for d, day := range days {
+		bitmapKey, setKey := "dau:"+day, "dau:set:"+day
+		bitmapKeys = append(bitmapKeys, bitmapKey)
+		setKeys = append(setKeys, setKey)
+
+		// Users 123, 456 and 789 from the online users example log in daily,
+		// everyone else with a probability that drops during the week
+		pipe := rdb.Pipeline()
+		for id := 1; id <= users; id++ {
+			if id == 123 || id == 456 || id == 789 || rng.Intn(100) < 60-5*d {
+				pipe.SetBit(ctx, bitmapKey, int64(id), 1)
+				pipe.SAdd(ctx, setKey, "user:"+strconv.Itoa(id))
+			}
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	fmt.Printf("   Recorded a week of activity for %d users\n", users)
+
+	active, err := rdb.GetBit(ctx, "dau:mon", 123).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   GETBIT dau:mon 123 = %d (user:123 was active on Monday)\n", active)
+	expect("GETBIT", "user 123 active on monday", active, Exactly(int64(1)))
+
+	active, _ = rdb.GetBit(ctx, "dau:mon", users+1).Result()
+	fmt.Printf("   GETBIT dau:mon %d = %d (bits past the end read as 0)\n", users+1, active)
+	expect("GETBIT", "bit past the end", active, Exactly(int64(0)))
+	printMemory(ctx, rdb, "dau:mon", "dau:set:mon")
+
+	// BITCOUNT - Count active users per day
+	fmt.Println("\n2. Counting daily active users with BITCOUNT:")
+	for i, day := range days {
+		count, err := rdb.BitCount(ctx, bitmapKeys[i], nil).Result()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		exact, _ := rdb.SCard(ctx, setKeys[i]).Result()
+		fmt.Printf("   %s: %d active\n", day, count)
+		expect("BITCOUNT", day+" active users", count, Exactly(exact))
+	}
+	// Sunday has the fewest active users, the bitmap stays the same size
+	printMemory(ctx, rdb, "dau:sun", "dau:set:sun")
+
+	// BITOP AND / OR - Combine days
+	fmt.Println("\n3. Combining days with BITOP AND and OR:")
+	everyDay, anyDay := "dau:week:every", "dau:week:any"
+	andCount, err := bitopCount(ctx, rdb, "AND", everyDay, bitmapKeys)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	orCount, _ := bitopCount(ctx, rdb, "OR", anyDay, bitmapKeys)
+	fmt.Printf("   Active every day this week (AND): %d\n", andCount)
+	fmt.Printf("   Active at least once this week (OR): %d\n", orCount)
+
+	setEvery, setAny := "dau:set:week:every", "dau:set:week:any"
+	exactAnd, _ := rdb.SInterStore(ctx, setEvery, setKeys...).Result()
+	exactOr, _ := rdb.SUnionStore(ctx, setAny, setKeys...).Result()
+	expect("BITOP", "active every day", andCount, Exactly(exactAnd))
+	expect("BITOP", "active any day", orCount, Exactly(exactOr))
+	printMemory(ctx, rdb, anyDay, setAny)
+
+	// Cohort retention - Of Monday's users, how many came back on Sunday?
+	fmt.Println("\n4. Cohort retention with BITOP AND:")
+	retained := "dau:retained:mon:sun"
+	cohort, _ := rdb.BitCount(ctx, "dau:mon", nil).Result()
+	kept, err := bitopCount(ctx, rdb, "AND", retained, []string{"dau:mon", "dau:sun"})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Monday cohort: %d users, still active Sunday: %d (%.1f%% retention)\n",
+		cohort, kept, float64(kept)/float64(cohort)*100)
+
+	setRetained := "dau:set:retained:mon:sun"
+	exactKept, _ := rdb.SInterStore(ctx, setRetained, "dau:set:mon", "dau:set:sun").Result()
+	expect("BITOP", "monday users retained on sunday", kept, Exactly(exactKept))
+	printMemory(ctx, rdb, retained, setRetained)
+
+	// BITPOS - Find the first set or clear bit
+	fmt.Println("\n5. Finding users with BITPOS:")
+	firstActive, err := rdb.BitPos(ctx, everyDay, 1).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Lowest user ID active every day: %d\n", firstActive)
+	expect("BITPOS", "lowest user active every day", firstActive, InRange(1, 123))
+
+	firstIdle, _ := rdb.BitPos(ctx, "dau:sun", 0).Result()
+	fmt.Printf("   First clear bit on Sunday: %d (bit 0 is unused since IDs start at 1)\n", firstIdle)
+	expect("BITPOS", "first clear bit on sunday", firstIdle, Exactly(int64(0)))
+
+	// Bitmaps size to the highest bit set, so sparse high IDs are costly
+	sparse := "dau:sparse"
+	rdb.SetBit(ctx, sparse, 10_000_000, 1)
+	sparseBytes, _ := rdb.StrLen(ctx, sparse).Result()
+	fmt.Printf("   One user with ID 10,000,000 needs a %d byte bitmap, use dense IDs\n", sparseBytes)
+	expect("SETBIT", "bitmap size for bit 10,000,000", sparseBytes, Exactly(int64(1_250_001)))
+	setSparse := "dau:set:sparse"
+	rdb.SAdd(ctx, setSparse, "user:10000000")
+	printMemory(ctx, rdb, sparse, setSparse)
+
+	// BITFIELD - Pack many small counters into one key
+	fmt.Println("\n6. Packing per-user counters with BITFIELD:")
+	counters := "pageviews:u8"
+	pipe := rdb.Pipeline()
+	hashCounters := "pageviews:hash"
+	for id := 1; id <= users; id++ {
+		views := rng.Intn(50)
+		// u8 at offset #id is the id-th 8-bit slot
+		pipe.BitField(ctx, counters, "SET", "u8", "#"+strconv.Itoa(id), views)
+		pipe.HSet(ctx, hashCounters, strconv.Itoa(id), views)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Stored %d 8-bit page view counters in one string\n", users)
+
+	res, err := rdb.BitField(ctx, counters,
+		"SET", "u8", "#123", 250,
+		"OVERFLOW", "SAT", "INCRBY", "u8", "#123", 10,
+		"GET", "u8", "#123",
+	).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   user 123: set to 250, INCRBY 10 with OVERFLOW SAT -> %d (saturates at 255)\n", res[2])
+	expect("BITFIELD", "saturating u8 counter", res[2], Exactly(int64(255)))
+
+	res, _ = rdb.BitField(ctx, counters, "OVERFLOW", "WRAP", "INCRBY", "u8", "#123", 1).Result()
+	fmt.Printf("   user 123: INCRBY 1 with OVERFLOW WRAP -> %d\n", res[0])
+	expect("BITFIELD", "wrapping u8 counter", res[0], Exactly(int64(0)))
+
+	// A Set cannot hold counts, so the natural alternative here is a hash
+	fmt.Println("   Compared with a hash of user ID -> count:")
+	printMemory(ctx, rdb, counters, hashCounters)
+
+	// Cleanup
+	fmt.Println("\n7. Cleanup:")
+	keys := append([]string{everyDay, anyDay, retained, sparse, counters, setEvery, setAny, setRetained, setSparse, hashCounters}, bitmapKeys...)
+	rdb.Del(ctx, append(keys, setKeys...)...)
+	fmt.Println("   Cleaned up bitmap examples ✓")
+}
+
+// bitopCount runs BITOP op into dest and returns the number of set bits
+func bitopCount(ctx context.Context, rdb *redis.Client, op, dest string, keys []string) (int64, error) {
+	var err error
+	switch op {
+	case "AND":
+		err = rdb.BitOpAnd(ctx, dest, keys...).Err()
+	case "OR":
+		err = rdb.BitOpOr(ctx, dest, keys...).Err()
+	}
+	if err != nil {
+		return 0, err
+	}
+	return rdb.BitCount(ctx, dest, nil).Result()
+}
+
+// printMemory prints MEMORY USAGE of a compact key next to its alternative
+func printMemory(ctx context.Context, rdb *redis.Client, compact, alternative string) {
+	compactBytes, err := rdb.MemoryUsage(ctx, compact, 0).Result()
+	if err != nil {
+		fmt.Printf("   MEMORY USAGE not available: %v\n", err)
+		return
+	}
+	altBytes, _ := rdb.MemoryUsage(ctx, alternative, 0).Result()
+	fmt.Printf("   Memory: %s = %d bytes, %s = %d bytes\n", compact, compactBytes, alternative, altBytes)
+}
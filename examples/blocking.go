@@ -0,0 +1,267 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunBlockingExamples demonstrates blocking list and sorted set commands
+func RunBlockingExamples(rdb *redis.Client) {
+	fmt.Println("\n Blocking Operations")
+	fmt.Println("======================")
+
+	ctx := context.Background()
+	jobs := "blocking:jobs"
+	high, low := "blocking:high", "blocking:low"
+	src, dst := "blocking:inbox", "blocking:processing"
+	scores := "blocking:bids"
+	rdb.Del(ctx, jobs, high, low, src, dst, scores, "blocking:a", "blocking:b")
+
+	// BLPOP on an empty list - waits, then gives up with redis.Nil
+	fmt.Println("1. BLPOP on an empty list with a 1s timeout:")
+	start := time.Now()
+	_, err := rdb.BLPop(ctx, time.Second, jobs).Result()
+	fmt.Printf("   Returned %v after %v\n", err, time.Since(start).Round(100*time.Millisecond))
+	expect("BLPOP", "timeout on an empty list", err, Exactly(redis.Nil))
+
+	// Blocked clients are served in the order they started waiting
+	fmt.Println("\n2. A producer and three blocked consumers:")
+	var mu sync.Mutex
+	served := make(map[string]string)
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		consumer := fmt.Sprintf("consumer-%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// BLPOP returns the key and the element
+			res, err := rdb.BLPop(ctx, 5*time.Second, jobs).Result()
+			if err != nil {
+				fmt.Printf("   %s: %v\n", consumer, err)
+				return
+			}
+			fmt.Printf("   %s got %s\n", consumer, res[1])
+			mu.Lock()
+			served[consumer] = res[1]
+			mu.Unlock()
+		}()
+		// Stagger the consumers so they block in a known order
+		time.Sleep(50 * time.Millisecond)
+	}
+	for i := 1; i <= 3; i++ {
+		item := fmt.Sprintf("job-%d", i)
+		fmt.Printf("   producer RPUSH %s\n", item)
+		rdb.RPush(ctx, jobs, item)
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+	expect("BLPOP", "longest waiting consumer served first", served, Exactly(map[string]string{
+		"consumer-1": "job-1", "consumer-2": "job-2", "consumer-3": "job-3",
+	}))
+
+	// BRPOP over several keys checks them in the order given
+	fmt.Println("\n3. BRPOP over several keys as a priority queue:")
+	rdb.RPush(ctx, low, "low-1")
+	rdb.RPush(ctx, high, "high-1")
+	var order []string
+	for i := 0; i < 2; i++ {
+		res, err := rdb.BRPop(ctx, time.Second, high, low).Result()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("   BRPOP %s %s -> %s from %s\n", high, low, res[1], res[0])
+		order = append(order, res[1])
+	}
+	expect("BRPOP", "high priority key first", order, Exactly([]string{"high-1", "low-1"}))
+
+	// BLMOVE hands an element over into another list in one step, so it is
+	// never only in the consumer's memory
+	fmt.Println("\n4. BLMOVE waiting for an element to move:")
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		rdb.RPush(ctx, src, "message-1")
+	}()
+	start = time.Now()
+	moved, err := rdb.BLMove(ctx, src, dst, "LEFT", "RIGHT", 2*time.Second).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	inFlight, _ := rdb.LRange(ctx, dst, 0, -1).Result()
+	fmt.Printf("   Woke after %v with %s, %s now holds %v\n", time.Since(start).Round(10*time.Millisecond), moved, dst, inFlight)
+	expect("BLMOVE", "moved element", inFlight, Exactly([]string{"message-1"}))
+
+	// BLMPOP pops several elements from the first non-empty list
+	fmt.Println("\n5. BLMPOP popping a batch:")
+	runBLMPop(ctx, rdb)
+
+	// BZPOPMIN/BZPOPMAX block on sorted sets
+	fmt.Println("\n6. BZPOPMIN and BZPOPMAX on a sorted set:")
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		rdb.ZAdd(ctx, scores, redis.Z{Score: 120, Member: "bob"}, redis.Z{Score: 90, Member: "alice"}, redis.Z{Score: 150, Member: "carol"})
+	}()
+	lowest, err := rdb.BZPopMin(ctx, 2*time.Second, scores).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   BZPOPMIN waited for the ZADD, got %s (%.0f)\n", lowest.Member, lowest.Score)
+	highest, err := rdb.BZPopMax(ctx, time.Second, scores).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   BZPOPMAX returned at once, got %s (%.0f)\n", highest.Member, highest.Score)
+	expect("BZPOPMIN", "lowest bid", lowest.Member, Exactly("alice"))
+	expect("BZPOPMAX", "highest bid", highest.Member, Exactly("carol"))
+
+	// Context cancellation - go-redis only applies context deadlines to
+	// socket reads with ContextTimeoutEnabled, otherwise a BLPOP with timeout
+	// 0 waits forever whatever the context says
+	fmt.Println("\n7. Cancelling a BLPOP that would wait forever:")
+	opts := *rdb.Options()
+	opts.ContextTimeoutEnabled = true
+	cancellable := newClient(&opts)
+	defer cancellable.Close()
+	cancelCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	start = time.Now()
+	_, err = cancellable.BLPop(cancelCtx, 0, jobs).Result()
+	cancel()
+	fmt.Printf("   BLPOP with timeout 0 returned %v after %v\n", err, time.Since(start).Round(100*time.Millisecond))
+	// The deadline surfaces as a network timeout on the read
+	var netErr net.Error
+	expect("BLPOP", "cancelled by context", errors.As(err, &netErr) && netErr.Timeout(), Exactly(true))
+	// The server would still answer the BLPOP later, so go-redis closes the
+	// connection instead of returning it to the pool, which also unblocks
+	// the server side once it notices the close
+	if err := waitForUnblocked(ctx, rdb, "blpop"); err != nil {
+		fmt.Printf("   CLIENT LIST not available (%v), skipping the check that nobody is still blocked\n", err)
+	} else {
+		rdb.RPush(ctx, jobs, "after-cancel")
+		left, _ := rdb.LLen(ctx, jobs).Result()
+		fmt.Printf("   A later push stays in the list (LLEN %d), nobody is still blocked on it\n", left)
+		expect("LLEN", "element not taken by the cancelled BLPOP", left, Exactly(int64(1)))
+	}
+
+	// Each blocking call holds a pool connection for as long as it waits
+	fmt.Println("\n8. Why blocking calls need their own connections:")
+	runPoolStarvation(ctx, rdb)
+
+	// Cleanup
+	rdb.Del(ctx, jobs, high, low, src, dst, scores, "blocking:a", "blocking:b")
+	fmt.Println("\n9. Cleanup: Cleaned up blocking examples ✓")
+}
+
+// waitForUnblocked polls CLIENT LIST for up to a second until no client is
+// blocked in command. It only returns an error if CLIENT LIST fails.
+func waitForUnblocked(ctx context.Context, rdb *redis.Client, command string) error {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		list, err := rdb.ClientList(ctx).Result()
+		if err != nil {
+			return err
+		}
+		if !blockedIn(list, command) {
+			return nil
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return nil
+}
+
+// blockedIn reports whether a CLIENT LIST reply has a client blocked in
+// command, flagged b
+func blockedIn(list, command string) bool {
+	for _, line := range strings.Split(list, "\n") {
+		fields := make(map[string]string)
+		for _, field := range strings.Fields(line) {
+			if key, value, ok := strings.Cut(field, "="); ok {
+				fields[key] = value
+			}
+		}
+		if fields["cmd"] == command && strings.Contains(fields["flags"], "b") {
+			return true
+		}
+	}
+	return false
+}
+
+// runBLMPop demonstrates BLMPOP, which needs Redis 7.0
+func runBLMPop(ctx context.Context, rdb *redis.Client) {
+	version, major, err := serverMajorVersion(ctx, rdb)
+	if err != nil {
+		fmt.Printf("   Could not read the server version (%v), skipping\n", err)
+		return
+	}
+	if major < 7 {
+		fmt.Printf("   Server is version %s, BLMPOP needs 7.0 or newer, skipping\n", version)
+		return
+	}
+	rdb.RPush(ctx, "blocking:b", "b1", "b2", "b3", "b4")
+	key, elems, err := rdb.BLMPop(ctx, time.Second, "left", 3, "blocking:a", "blocking:b").Result()
+	if err != nil {
+		fmt.Printf("   Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   BLMPOP 1 2 blocking:a blocking:b LEFT COUNT 3 -> %v from %s (blocking:a was empty)\n", elems, key)
+	expect("BLMPOP", "batch from the first non-empty list", elems, Exactly([]string{"b1", "b2", "b3"}))
+}
+
+// runPoolStarvation fills a small pool with blocked calls, so that an
+// ordinary command cannot get a connection, then shows the fix of giving
+// blocking consumers a client of their own
+func runPoolStarvation(ctx context.Context, rdb *redis.Client) {
+	opts := *rdb.Options()
+	opts.PoolSize = 2
+	opts.PoolTimeout = 300 * time.Millisecond
+	// go-redis retries pool timeouts, which would only hide the wait
+	opts.MaxRetries = -1
+	small := newClient(&opts)
+	defer small.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.PoolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			small.BLPop(ctx, time.Second, "blocking:starved")
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	err := small.Get(ctx, "blocking:anything").Err()
+	fmt.Printf("   Pool of %d, both in BLPOP: GET returned %v after %v\n",
+		opts.PoolSize, err, time.Since(start).Round(100*time.Millisecond))
+	expect("GET", "GET while the pool is blocked", err, Exactly(redis.ErrPoolTimeout))
+	wg.Wait()
+
+	// Blocking consumers on a client of their own leave the main pool free
+	blockers := newClient(&opts)
+	defer blockers.Close()
+	for i := 0; i < opts.PoolSize; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			blockers.BLPop(ctx, time.Second, "blocking:starved")
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	start = time.Now()
+	err = small.Get(ctx, "blocking:anything").Err()
+	fmt.Printf("   Blockers on their own client: GET returned %v after %v\n",
+		err, time.Since(start).Round(time.Millisecond))
+	expect("GET", "GET with blockers on their own client", err, Exactly(redis.Nil))
+	wg.Wait()
+
+	stats := small.PoolStats()
+	fmt.Printf("   Pool timeouts on the starved client: %d\n", stats.Timeouts)
+}
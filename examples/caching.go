@@ -2,7 +2,11 @@ package examples
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"redis-playground/cache"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -23,8 +27,12 @@ func RunCachingExamples(rdb *redis.Client) {
 	if err == redis.Nil {
 		fmt.Println("   Cache miss! Fetching from DB...")
 		val = dbValue
-		rdb.Set(ctx, cacheKey, val, 10*time.Second)
-		fmt.Println("   Value cached in Redis")
+		if err := rdb.Set(ctx, cacheKey, val, scaled(10*time.Second)).Err(); err != nil {
+			// The value is still good, it just won't be cached this time
+			fmt.Printf("   Could not cache value: %v\n", err)
+		} else {
+			fmt.Println("   Value cached in Redis")
+		}
 	} else if err != nil {
 		fmt.Printf("   Redis error: %v\n", err)
 		return
@@ -32,14 +40,17 @@ func RunCachingExamples(rdb *redis.Client) {
 		fmt.Println("   Cache hit!")
 	}
 	fmt.Printf("   Value: %s\n", val)
+	expect("GET", "cache-aside value", val, Exactly(dbValue))
 
 	// 2. Expiring cache
 	fmt.Println("\n2. Expiring cache:")
-	rdb.Set(ctx, "cache:expiring", "temporary", 3*time.Second)
+	rdb.Set(ctx, "cache:expiring", "temporary", scaled(3*time.Second))
 	val, _ = rdb.Get(ctx, "cache:expiring").Result()
 	fmt.Printf("   Value before expire: %s\n", val)
-	time.Sleep(4 * time.Second)
+	expect("GET", "value before expire", val, Exactly("temporary"))
+	waitForExpiry(ctx, rdb, "cache:expiring", 4*time.Second)
 	val, err = rdb.Get(ctx, "cache:expiring").Result()
+	expect("GET", "value after expire", err, Exactly(redis.Nil))
 	if err == redis.Nil {
 		fmt.Println("   Value after expire: (cache expired)")
 	}
@@ -49,6 +60,7 @@ func RunCachingExamples(rdb *redis.Client) {
 	rdb.Set(ctx, "cache:invalidate", "stale", 0)
 	rdb.Del(ctx, "cache:invalidate")
 	val, err = rdb.Get(ctx, "cache:invalidate").Result()
+	expect("DEL", "value after invalidation", err, Exactly(redis.Nil))
 	if err == redis.Nil {
 		fmt.Println("   Value after invalidation: (no cache)")
 	}
@@ -60,14 +72,214 @@ func RunCachingExamples(rdb *redis.Client) {
 	if err == redis.Nil {
 		fmt.Println("   Cache miss! Running expensive operation...")
 		val = "Expensive Result"
-		rdb.Set(ctx, expensiveKey, val, 5*time.Second)
-		fmt.Println("   Computed result cached")
+		if err := rdb.Set(ctx, expensiveKey, val, scaled(5*time.Second)).Err(); err != nil {
+			fmt.Printf("   Could not cache result: %v\n", err)
+		} else {
+			fmt.Println("   Computed result cached")
+		}
 	} else {
 		fmt.Println("   Cache hit!")
 	}
 	fmt.Printf("   Expensive operation result: %s\n", val)
+	expect("GET", "expensive operation result", val, Exactly("Expensive Result"))
+
+	// Typed cache with a loader, built on the same cache-aside pattern
+	if !runTypedCacheExamples(ctx, rdb) {
+		return
+	}
+
+	// Expiry of a hot key, with and without stampede protection
+	runStampedeExamples(ctx, rdb)
 
 	// Cleanup
 	rdb.Del(ctx, cacheKey, "cache:expiring", "cache:invalidate", expensiveKey)
-	fmt.Println("\n5. Cleanup: Cleaned up caching examples ✓")
+	fmt.Println("\n7. Cleanup: Cleaned up caching examples ✓")
+}
+
+// cachedUser is the value type for the typed cache example
+type cachedUser struct {
+	ID   string
+	Name string
+}
+
+// runTypedCacheExamples shows cache.Cache in front of a slow "database",
+// returning false if the example had to stop early
+func runTypedCacheExamples(ctx context.Context, rdb *redis.Client) bool {
+	fmt.Println("\n5. Typed cache with a loader (cache package):")
+
+	db := map[string]cachedUser{"42": {ID: "42", Name: "Naim"}, "7": {ID: "7", Name: "Ada"}}
+	var loads atomic.Int64
+	loader := func(ctx context.Context, id string) (cachedUser, error) {
+		loads.Add(1)
+		time.Sleep(50 * time.Millisecond) // a slow query
+		if id == "500" {
+			return cachedUser{}, errors.New("database timeout")
+		}
+		u, ok := db[id]
+		if !ok {
+			return cachedUser{}, cache.ErrNotFound
+		}
+		return u, nil
+	}
+
+	users := cache.New(rdb, loader, cache.Options{
+		Prefix:      "cache:typed:user:",
+		TTL:         scaled(10 * time.Second),
+		Jitter:      0.1,
+		NegativeTTL: scaled(2 * time.Second),
+	})
+	for _, id := range []string{"42", "7", "404", "500"} {
+		users.Delete(ctx, id)
+	}
+
+	// Concurrent misses for one key share a single load
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			users.Fetch(ctx, "42")
+		}()
+	}
+	wg.Wait()
+	stats := users.Stats()
+	fmt.Printf("   20 concurrent Fetch(42): %d load, %d callers shared it\n", loads.Load(), stats.Shared)
+	expect("GET", "loads for concurrent misses", loads.Load(), Exactly(int64(1)))
+
+	u, err := users.Fetch(ctx, "42")
+	if err != nil {
+		fmt.Printf("   Error: %v\n", err)
+		return false
+	}
+	fmt.Printf("   Fetch(42) again: %+v, hits %d\n", u, users.Stats().Hits)
+	expect("GET", "typed cache value", u, Exactly(cachedUser{ID: "42", Name: "Naim"}))
+
+	// Not-found results are cached too, so the loader is not hammered
+	before := loads.Load()
+	for i := 0; i < 3; i++ {
+		_, err = users.Fetch(ctx, "404")
+	}
+	fmt.Printf("   Fetch(404) x3: %v, loader called %d time(s)\n", err, loads.Load()-before)
+	expect("GET", "negative cache loads", loads.Load()-before, Exactly(int64(1)))
+
+	// Each error kind can be told apart
+	_, err = users.Get(ctx, "7")
+	fmt.Printf("   Get(7) without loading: %v (errors.Is ErrMiss = %v)\n", err, errors.Is(err, cache.ErrMiss))
+	_, err = users.Fetch(ctx, "500")
+	var loaderErr *cache.LoaderError
+	fmt.Printf("   Fetch(500): %v (LoaderError = %v)\n", err, errors.As(err, &loaderErr))
+	expect("GET", "loader failure is a LoaderError", errors.As(err, &loaderErr), Exactly(true))
+
+	// TTL jitter keeps entries written together from expiring together
+	var ttls []string
+	for _, id := range []string{"42", "7"} {
+		users.Fetch(ctx, id)
+		ttl, _ := rdb.PTTL(ctx, "cache:typed:user:"+id).Result()
+		ttls = append(ttls, ttl.String())
+	}
+	fmt.Printf("   TTLs with ±10%% jitter: %v\n", ttls)
+
+	// The gob codec stores the same values in Go's binary format
+	gobUsers := cache.New(rdb, loader, cache.Options{Prefix: "cache:typed:gob:", TTL: scaled(10 * time.Second), Codec: cache.Gob})
+	gobUsers.Delete(ctx, "7")
+	u, err = gobUsers.Fetch(ctx, "7")
+	fmt.Printf("   Gob codec Fetch(7): %+v %v\n", u, errString(err))
+	expect("GET", "gob codec value", u, Exactly(cachedUser{ID: "7", Name: "Ada"}))
+
+	// A Redis outage is its own error, or falls through to the loader
+	down := newClient(&redis.Options{Addr: "localhost:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer down.Close()
+	strict := cache.New(down, loader, cache.Options{})
+	_, err = strict.Fetch(ctx, "42")
+	var outage *cache.OutageError
+	fmt.Printf("   Redis down, fail closed: %v\n", err)
+	expect("GET", "outage is an OutageError", errors.As(err, &outage), Exactly(true))
+
+	open := cache.New(down, loader, cache.Options{FailOpen: true})
+	u, err = open.Fetch(ctx, "42")
+	fmt.Printf("   Redis down, fail open: %+v %v\n", u, errString(err))
+	expect("GET", "fail open value", u.Name, Exactly("Naim"))
+
+	rdb.Del(ctx, "cache:typed:user:42", "cache:typed:user:7", "cache:typed:user:404", "cache:typed:gob:7")
+	return true
+}
+
+// runStampedeExamples hammers an expiring key from many goroutines spread
+// over simulated app servers, once per stampede strategy, and counts how
+// often the expensive value is recomputed
+func runStampedeExamples(ctx context.Context, rdb *redis.Client) {
+	fmt.Println("\n6. Stampede protection when a hot key expires:")
+
+	const (
+		servers    = 50 // each with its own Cache, like separate processes
+		perServer  = 4
+		goroutines = servers * perServer
+	)
+	// Fast mode keeps the recompute time small next to the TTL, but not so
+	// small that the readers outrun it
+	var (
+		ttl       = scaledAtLeast(2*time.Second, 200*time.Millisecond)
+		recompute = scaledAtLeast(100*time.Millisecond, 10*time.Millisecond)
+		interval  = scaledAtLeast(20*time.Millisecond, 10*time.Millisecond)
+		runFor    = ttl + ttl/4
+	)
+	fmt.Printf("   %d goroutines on %d servers read the key every %v, it lives %v and takes %v to compute\n",
+		goroutines, servers, interval, ttl, recompute)
+
+	for _, strategy := range []cache.Stampede{cache.StampedeNone, cache.StampedeLock, cache.StampedeXFetch} {
+		var recomputes atomic.Int64
+		loader := func(ctx context.Context, key string) (string, error) {
+			recomputes.Add(1)
+			time.Sleep(recompute)
+			return "Expensive Result", nil
+		}
+
+		caches := make([]*cache.Cache[string], servers)
+		for i := range caches {
+			caches[i] = cache.New(rdb, loader, cache.Options{
+				Prefix:   "cache:expensive:",
+				TTL:      ttl,
+				Stampede: strategy,
+			})
+		}
+		key := strategy.String()
+		caches[0].Delete(ctx, key)
+		caches[0].Fetch(ctx, key) // warm the cache
+		recomputes.Store(0)
+
+		var wg sync.WaitGroup
+		deadline := time.Now().Add(runFor)
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(c *cache.Cache[string]) {
+				defer wg.Done()
+				for time.Now().Before(deadline) {
+					c.Fetch(ctx, key)
+					time.Sleep(interval)
+				}
+			}(caches[g%servers])
+		}
+		wg.Wait()
+
+		var misses, early, waits int64
+		for _, c := range caches {
+			stats := c.Stats()
+			misses += stats.Misses
+			early += stats.EarlyRefreshes
+			waits += stats.LockWaits
+		}
+		misses-- // the warm-up miss
+		fmt.Printf("   %-7s recomputations %3d, misses %4d, early refreshes %d, lock waits %d\n",
+			strategy, recomputes.Load(), misses, early, waits)
+
+		switch strategy {
+		case cache.StampedeLock:
+			expect("SET NX PX", "recomputations with a lock", recomputes.Load(), Exactly(int64(1)))
+		case cache.StampedeXFetch:
+			expect("PTTL", "misses with xfetch", misses, Exactly(int64(0)))
+		}
+		caches[0].Delete(ctx, key)
+	}
+	fmt.Println("   none lets every server recompute at expiry, lock makes them wait for one,")
+	fmt.Println("   xfetch refreshes before expiry so readers never miss and never wait")
 }
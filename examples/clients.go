@@ -0,0 +1,71 @@
+package examples
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var clientHooks struct {
+	sync.Mutex
+	hooks []redis.Hook
+}
+
+// SetClientHooks sets hooks for the connections examples open on their own,
+// next to the client they are given: extra clients, subscriptions and the
+// near cache's connections. Add the same hooks to the main client to see
+// every command an example sends.
+func SetClientHooks(hooks ...redis.Hook) {
+	clientHooks.Lock()
+	clientHooks.hooks = hooks
+	clientHooks.Unlock()
+}
+
+// exampleHooks returns the hooks set with SetClientHooks
+func exampleHooks() []redis.Hook {
+	clientHooks.Lock()
+	defer clientHooks.Unlock()
+	return append([]redis.Hook(nil), clientHooks.hooks...)
+}
+
+// newClient creates a client with the hooks set with SetClientHooks
+func newClient(opts *redis.Options) *redis.Client {
+	client := redis.NewClient(opts)
+	for _, hook := range exampleHooks() {
+		client.AddHook(hook)
+	}
+	return client
+}
+
+// confirmSubscription waits for the server to confirm a SUBSCRIBE-style
+// command. go-redis sends those outside the client's hooks, so the
+// confirmation is passed through the hooks set with SetClientHooks instead.
+func confirmSubscription(ctx context.Context, pubsub *redis.PubSub, command string, channels ...string) error {
+	args := make([]interface{}, 0, len(channels)+1)
+	args = append(args, command)
+	for _, ch := range channels {
+		args = append(args, ch)
+	}
+	cmd := redis.NewCmd(ctx, args...)
+
+	process := func(ctx context.Context, cmd redis.Cmder) error {
+		c := cmd.(*redis.Cmd)
+		for range channels {
+			reply, err := pubsub.Receive(ctx)
+			if err != nil {
+				c.SetErr(err)
+				return err
+			}
+			if sub, ok := reply.(*redis.Subscription); ok {
+				c.SetVal([]interface{}{sub.Kind, sub.Channel, int64(sub.Count)})
+			}
+		}
+		return nil
+	}
+	hooks := exampleHooks()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		process = hooks[i].ProcessHook(process)
+	}
+	return process(ctx, cmd)
+}
@@ -0,0 +1,250 @@
+package examples
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Dataset names accepted by UseDataset
+const (
+	DatasetLeaderboard = "leaderboard"
+	DatasetReadings    = "readings"
+	DatasetProfiles    = "profiles"
+	DatasetTags        = "tags"
+)
+
+// Article is an article ID and its tags
+type Article struct {
+	ID   string   `json:"article"`
+	Tags []string `json:"tags"`
+}
+
+// maxListed caps how many entries are printed when a large dataset is loaded
+const maxListed = 10
+
+// profileFields are the fields the hash example reads from every profile
+var profileFields = []string{"name", "email", "age", "location", "role"}
+
+// Built-in datasets, replaced by UseDataset
+var (
+	leaderboardData = []redis.Z{
+		{Score: 1500, Member: "alice"},
+		{Score: 2300, Member: "bob"},
+		{Score: 1800, Member: "charlie"},
+		{Score: 2100, Member: "diana"},
+		{Score: 1200, Member: "eve"},
+	}
+
+	// Reading members are "<timestamp>:<value>", since equal values at
+	// different times would otherwise be one member
+	readingData = []redis.Z{
+		{Score: 1640995200, Member: "1640995200:22.5"},
+		{Score: 1640995260, Member: "1640995260:23.1"},
+		{Score: 1640995320, Member: "1640995320:22.8"},
+		{Score: 1640995380, Member: "1640995380:23.4"},
+		{Score: 1640995440, Member: "1640995440:23.0"},
+	}
+
+	profileData = []map[string]string{{
+		"name":     "John Doe",
+		"email":    "john@example.com",
+		"age":      "30",
+		"location": "San Francisco",
+		"role":     "Developer",
+	}}
+
+	articleData = []Article{
+		{ID: "1", Tags: []string{"redis", "database", "nosql", "performance"}},
+		{ID: "2", Tags: []string{"golang", "programming", "performance", "backend"}},
+		{ID: "3", Tags: []string{"redis", "golang", "tutorial", "backend"}},
+	}
+)
+
+// UseDataset loads the named dataset from a JSON or CSV file, chosen by
+// extension, and uses it in place of the built-in data
+//
+// File shapes:
+//
+//	leaderboard  JSON [{"member": "alice", "score": 1500}]      CSV member,score
+//	readings     JSON [{"timestamp": 1640995200, "value": "22.5"}] CSV timestamp,value
+//	             (stored as member "<timestamp>:<value>")
+//	profiles     JSON [{"name": "...", "email": "...", ...}]    CSV with one column per field
+//	tags         JSON [{"article": "1", "tags": ["redis"]}]     CSV article,tags (tags separated by ;)
+func UseDataset(name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	isCSV := strings.EqualFold(filepath.Ext(path), ".csv")
+
+	switch name {
+	case DatasetLeaderboard:
+		z, err := loadScored(f, isCSV, "member", "score")
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		leaderboardData = z
+	case DatasetReadings:
+		z, err := loadScored(f, isCSV, "value", "timestamp")
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for i := range z {
+			z[i].Member = readingMember(z[i].Score, z[i].Member.(string))
+		}
+		readingData = z
+	case DatasetProfiles:
+		p, err := loadProfiles(f, isCSV)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		profileData = p
+	case DatasetTags:
+		a, err := loadArticles(f, isCSV)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		articleData = a
+	default:
+		return fmt.Errorf("unknown dataset %q", name)
+	}
+	return nil
+}
+
+// loadScored reads sorted set entries with the given member and score columns
+func loadScored(r io.Reader, isCSV bool, memberCol, scoreCol string) ([]redis.Z, error) {
+	rows, err := readRows(r, isCSV)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("dataset is empty")
+	}
+
+	out := make([]redis.Z, 0, len(rows))
+	for i, row := range rows {
+		member, ok := row[memberCol]
+		if !ok || member == "" {
+			return nil, fmt.Errorf("row %d: missing %s", i+1, memberCol)
+		}
+		score, err := strconv.ParseFloat(row[scoreCol], 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: bad %s: %w", i+1, scoreCol, err)
+		}
+		out = append(out, redis.Z{Score: score, Member: member})
+	}
+	return out, nil
+}
+
+// readingMember makes a reading's value unique by prefixing its timestamp
+func readingMember(timestamp float64, value string) string {
+	return strconv.FormatFloat(timestamp, 'f', -1, 64) + ":" + value
+}
+
+// readingValue returns the value part of a reading member
+func readingValue(member interface{}) string {
+	s := fmt.Sprint(member)
+	if _, value, ok := strings.Cut(s, ":"); ok {
+		return value
+	}
+	return s
+}
+
+func loadProfiles(r io.Reader, isCSV bool) ([]map[string]string, error) {
+	rows, err := readRows(r, isCSV)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("dataset is empty")
+	}
+
+	for i, row := range rows {
+		for _, field := range profileFields {
+			if _, ok := row[field]; !ok {
+				return nil, fmt.Errorf("row %d: missing %s", i+1, field)
+			}
+		}
+		// The hash example increments age with HINCRBY
+		if _, err := strconv.ParseInt(row["age"], 10, 64); err != nil {
+			return nil, fmt.Errorf("row %d: age %q is not an integer", i+1, row["age"])
+		}
+	}
+	return rows, nil
+}
+
+func loadArticles(r io.Reader, isCSV bool) ([]Article, error) {
+	var out []Article
+	if isCSV {
+		rows, err := readRows(r, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			out = append(out, Article{ID: row["article"], Tags: strings.Split(row["tags"], ";")})
+		}
+	} else if err := json.NewDecoder(r).Decode(&out); err != nil {
+		return nil, err
+	}
+
+	if len(out) == 0 {
+		return nil, fmt.Errorf("dataset is empty")
+	}
+	for i, a := range out {
+		if a.ID == "" || len(a.Tags) == 0 {
+			return nil, fmt.Errorf("row %d: missing article or tags", i+1)
+		}
+	}
+	return out, nil
+}
+
+// readRows reads a JSON array of flat objects, or a CSV file with a header
+// row, into one map per row
+func readRows(r io.Reader, isCSV bool) ([]map[string]string, error) {
+	if !isCSV {
+		var raw []map[string]interface{}
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		rows := make([]map[string]string, len(raw))
+		for i, obj := range raw {
+			rows[i] = make(map[string]string, len(obj))
+			for k, v := range obj {
+				rows[i][k] = fmt.Sprint(v)
+			}
+		}
+		return rows, nil
+	}
+
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, col := range header {
+			if i < len(rec) {
+				row[strings.TrimSpace(col)] = strings.TrimSpace(rec[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -19,7 +19,7 @@ func RunExpirationTTLExamples(rdb *redis.Client) {
 
 	// SET with expiration
 	fmt.Println("1. Setting key with expiration (10 seconds):")
-	err := rdb.Set(ctx, key, value, 10*time.Second).Err()
+	err := rdb.Set(ctx, key, value, scaled(10*time.Second)).Err()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
@@ -27,12 +27,14 @@ func RunExpirationTTLExamples(rdb *redis.Client) {
 	fmt.Printf("   Key '%s' set with value '%s' and TTL 10s\n", key, value)
 
 	// Check TTL
-	ttl, err := rdb.TTL(ctx, key).Result()
+	ttlCmd := keyTTL(ctx, rdb, key)
+	ttl, err := ttlCmd.Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	fmt.Printf("   TTL for key '%s': %v\n", key, ttl)
+	expect(commandName(ttlCmd), "TTL after SET EX", ttl, Between(scaled(9*time.Second), scaled(10*time.Second)))
 
 	// Get value before expiration
 	val, err := rdb.Get(ctx, key).Result()
@@ -41,13 +43,15 @@ func RunExpirationTTLExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Value before expiration: %s\n", val)
+	expect("GET", "value before expiration", val, Exactly(value))
 
 	// Wait for 11 seconds to expire
 	fmt.Println("   Waiting for key to expire...")
-	time.Sleep(11 * time.Second)
+	waitForExpiry(ctx, rdb, key, 11*time.Second)
 
 	// Try to get value after expiration
 	val, err = rdb.Get(ctx, key).Result()
+	expect("GET", "value after expiration", err, Exactly(redis.Nil))
 	if err != nil {
 		fmt.Printf("   Value after expiration: (expired or missing)\n")
 	} else {
@@ -57,15 +61,17 @@ func RunExpirationTTLExamples(rdb *redis.Client) {
 	// EXPIRE/PEXPIRE - Set or update expiration
 	fmt.Println("\n2. Using EXPIRE to set/update expiration:")
 	rdb.Set(ctx, key, value, 0)
-	err = rdb.Expire(ctx, key, 5*time.Second).Err()
+	expireCmd := expire(ctx, rdb, key, 5*time.Second)
+	err = expireCmd.Err()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	fmt.Println("   Expiration updated to 5 seconds")
 
-	ttl, _ = rdb.TTL(ctx, key).Result()
+	ttl, _ = keyTTL(ctx, rdb, key).Result()
 	fmt.Printf("   New TTL: %v\n", ttl)
+	expect(commandName(expireCmd), "TTL after EXPIRE", ttl, Between(scaled(4*time.Second), scaled(5*time.Second)))
 
 	// PERSIST - Remove expiration from a key
 	fmt.Println("\n3. Using PERSIST to make key permanent:")
@@ -74,16 +80,18 @@ func RunExpirationTTLExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	ttl, _ = rdb.TTL(ctx, key).Result()
+	ttl, _ = keyTTL(ctx, rdb, key).Result()
 	fmt.Printf("   TTL after PERSIST: %v (should be -1 for permanent)\n", ttl)
+	expect("PERSIST", "TTL after PERSIST", ttl, Exactly(time.Duration(-1)))
 
 	// Practical example: Session expiration
 	fmt.Println("\n4. Practical example - Session expiration:")
 	sessionKey := "session:xyz"
-	rdb.Set(ctx, sessionKey, "user_data", 3*time.Second)
+	rdb.Set(ctx, sessionKey, "user_data", scaled(3*time.Second))
 	fmt.Println("   Session created with 3s TTL")
-	time.Sleep(4 * time.Second)
+	waitForExpiry(ctx, rdb, sessionKey, 4*time.Second)
 	_, err = rdb.Get(ctx, sessionKey).Result()
+	expect("GET", "session after expiry", err, Exactly(redis.Nil))
 	if err != nil {
 		fmt.Println("   Session expired and key deleted!")
 	} else {
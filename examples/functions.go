@@ -0,0 +1,180 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// counterLibrary is version 1 of the playground function library
+const counterLibrary = `#!lua name=playground
+
+local function counter_incr(keys, args)
+  return redis.call('INCRBY', keys[1], tonumber(args[1] or '1'))
+end
+
+local function counter_get(keys, args)
+  return tonumber(redis.call('GET', keys[1]) or '0')
+end
+
+redis.register_function('counter_incr', counter_incr)
+redis.register_function{
+  function_name = 'counter_get',
+  callback = counter_get,
+  flags = {'no-writes'},
+  description = 'Read a counter, callable with FCALL_RO',
+}
+`
+
+// counterLibraryV2 adds counter_reset, replacing version 1 in place
+const counterLibraryV2 = counterLibrary + `
+redis.register_function('counter_reset', function(keys, args)
+  return redis.call('DEL', keys[1])
+end)
+`
+
+// RunFunctionExamples demonstrates Redis 7 Functions
+func RunFunctionExamples(rdb *redis.Client) {
+	fmt.Println("\n Redis Functions")
+	fmt.Println("==================")
+
+	ctx := context.Background()
+	counter := "fn:counter"
+	library := "playground"
+
+	// Functions were added in Redis 7.0
+	version, major, err := serverMajorVersion(ctx, rdb)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if major < 7 {
+		fmt.Printf("   Server is version %s, Functions need 7.0 or newer, skipping\n", version)
+		return
+	}
+	fmt.Printf("   Server version %s\n", version)
+
+	// FUNCTION LOAD - Libraries live on the server and are persisted and replicated
+	fmt.Println("\n1. Loading a library with FUNCTION LOAD:")
+	rdb.FunctionDelete(ctx, library) // left over from an earlier run
+	rdb.Del(ctx, counter)
+	name, err := rdb.FunctionLoad(ctx, counterLibrary).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   FUNCTION LOAD -> library %q\n", name)
+	expect("FUNCTION LOAD", "library name", name, Exactly(library))
+
+	err = rdb.FunctionLoad(ctx, counterLibrary).Err()
+	fmt.Printf("   Loading it again without REPLACE fails: %v\n", err)
+	expect("FUNCTION LOAD", "duplicate load rejected", err != nil, Exactly(true))
+
+	// FCALL / FCALL_RO - Call functions by name
+	fmt.Println("\n2. Calling functions with FCALL and FCALL_RO:")
+	var value int64
+	for _, by := range []int{1, 5, 10} {
+		value, err = rdb.FCall(ctx, "counter_incr", []string{counter}, by).Int64()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("   FCALL counter_incr 1 %s %d -> %d\n", counter, by, value)
+	}
+	expect("FCALL", "counter after increments", value, Exactly(int64(16)))
+
+	// FCALL_RO only runs functions flagged no-writes, so it can go to replicas
+	value, err = rdb.FCallRO(ctx, "counter_get", []string{counter}).Int64()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   FCALL_RO counter_get 1 %s -> %d\n", counter, value)
+	expect("FCALL_RO", "counter read", value, Exactly(int64(16)))
+
+	err = rdb.FCallRO(ctx, "counter_incr", []string{counter}, 1).Err()
+	fmt.Printf("   FCALL_RO counter_incr is refused: %v\n", err)
+	expect("FCALL_RO", "write function refused", err != nil, Exactly(true))
+
+	// FUNCTION LOAD REPLACE - Ship a new version of the library
+	fmt.Println("\n3. Upgrading the library with FUNCTION LOAD REPLACE:")
+	if err := rdb.FunctionLoadReplace(ctx, counterLibraryV2).Err(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	deleted, _ := rdb.FCall(ctx, "counter_reset", []string{counter}).Int64()
+	fmt.Printf("   FCALL counter_reset 1 %s -> %d (new in version 2)\n", counter, deleted)
+	expect("FCALL", "counter_reset from version 2", deleted, Exactly(int64(1)))
+
+	// FUNCTION LIST - Inspect loaded libraries
+	fmt.Println("\n4. Inspecting libraries with FUNCTION LIST:")
+	libs, err := rdb.FunctionList(ctx, redis.FunctionListQuery{LibraryNamePattern: library}).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	var functions []string
+	for _, lib := range libs {
+		fmt.Printf("   library %s (engine %s)\n", lib.Name, lib.Engine)
+		for _, fn := range lib.Functions {
+			fmt.Printf("     %-14s flags %v %s\n", fn.Name, fn.Flags, fn.Description)
+			functions = append(functions, fn.Name)
+		}
+	}
+	expect("FUNCTION LIST", "functions in library", functions, SameMembers("counter_incr", "counter_get", "counter_reset"))
+
+	// FUNCTION DUMP / RESTORE - Copy libraries between servers or back them up
+	fmt.Println("\n5. Backing up with FUNCTION DUMP and FUNCTION RESTORE:")
+	dump, err := rdb.FunctionDump(ctx).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   FUNCTION DUMP -> %d byte payload of every library\n", len(dump))
+
+	rdb.FunctionDelete(ctx, library)
+	err = rdb.FCall(ctx, "counter_incr", []string{counter}).Err()
+	fmt.Printf("   After FUNCTION DELETE %s, FCALL fails: %v\n", library, err)
+
+	// The default APPEND policy fails if a library in the dump exists, and
+	// the dump holds every library on the server, so restore with REPLACE
+	if err := rdb.Do(ctx, "FUNCTION", "RESTORE", dump, "REPLACE").Err(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	value, err = rdb.FCall(ctx, "counter_incr", []string{counter}, 3).Int64()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   FUNCTION RESTORE ... REPLACE, then FCALL counter_incr 1 %s 3 -> %d\n", counter, value)
+	expect("FUNCTION RESTORE", "function callable after restore", value, Exactly(int64(3)))
+
+	err = rdb.FunctionRestore(ctx, dump).Err()
+	fmt.Printf("   FUNCTION RESTORE with the default APPEND policy: %v\n", err)
+
+	// Cleanup
+	fmt.Println("\n6. Cleanup:")
+	rdb.FunctionDelete(ctx, library)
+	rdb.Del(ctx, counter)
+	fmt.Println("   Cleaned up function examples ✓")
+}
+
+// serverMajorVersion returns the redis_version reported by INFO server and
+// its major number
+func serverMajorVersion(ctx context.Context, rdb *redis.Client) (string, int, error) {
+	info, err := rdb.Info(ctx, "server").Result()
+	if err != nil {
+		return "", 0, err
+	}
+	for _, line := range strings.Split(info, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+			major, _ := strconv.Atoi(strings.SplitN(v, ".", 2)[0])
+			return v, major, nil
+		}
+	}
+	return "", 0, fmt.Errorf("redis_version missing from INFO server")
+}
@@ -0,0 +1,233 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunGeoExamples demonstrates geospatial indexes and nearest-location searches
+func RunGeoExamples(rdb *redis.Client) {
+	fmt.Println("\n Geospatial Operations")
+	fmt.Println("========================")
+
+	ctx := context.Background()
+	storesKey := "stores:locations"
+	nearbyKey := "stores:nearby"
+	driversKey := "drivers:locations"
+
+	// A customer standing in Trafalgar Square
+	customerLon, customerLat := -0.1281, 51.5080
+
+	// GEOADD - Index store locations
+	fmt.Println("1. Adding store locations with GEOADD:")
+	stores := []*redis.GeoLocation{
+		{Name: "covent-garden", Longitude: -0.1226, Latitude: 51.5117},
+		{Name: "shoreditch", Longitude: -0.0780, Latitude: 51.5265},
+		{Name: "camden", Longitude: -0.1426, Latitude: 51.5390},
+		{Name: "greenwich", Longitude: -0.0077, Latitude: 51.4826},
+		{Name: "brixton", Longitude: -0.1145, Latitude: 51.4613},
+		{Name: "canary-wharf", Longitude: -0.0235, Latitude: 51.5054},
+		{Name: "notting-hill", Longitude: -0.2050, Latitude: 51.5090},
+	}
+	added, err := rdb.GeoAdd(ctx, storesKey, stores...).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Added %d stores\n", added)
+	expect("GEOADD", "stores added", added, Exactly(int64(len(stores))))
+
+	// GEOPOS - Read coordinates back
+	fmt.Println("\n2. Looking up stores with GEOPOS, GEODIST and GEOHASH:")
+	positions, err := rdb.GeoPos(ctx, storesKey, "covent-garden", "atlantis").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	// Coordinates come back slightly off, the index stores a 52-bit geohash
+	fmt.Printf("   GEOPOS covent-garden: %.6f, %.6f\n", positions[0].Longitude, positions[0].Latitude)
+	fmt.Printf("   GEOPOS atlantis: %v (unknown members are nil)\n", positions[1])
+	expect("GEOPOS", "covent-garden longitude", positions[0].Longitude, InRange(-0.1227, -0.1225))
+	expect("GEOPOS", "unknown member", positions[1] == nil, Exactly(true))
+
+	// GEODIST - Distance between two members
+	dist, err := rdb.GeoDist(ctx, storesKey, "covent-garden", "shoreditch", "km").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   GEODIST covent-garden shoreditch: %.2f km\n", dist)
+	expect("GEODIST", "covent-garden to shoreditch km", dist, InRange(3, 4))
+
+	// GEOHASH - Standard 11 character geohash strings
+	hashes, err := rdb.GeoHash(ctx, storesKey, "covent-garden", "camden").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   GEOHASH covent-garden camden: %v\n", hashes)
+	expect("GEOHASH", "geohash length", len(hashes[0]), Exactly(11))
+
+	// GEOSEARCH BYRADIUS - Nearest stores within a radius
+	fmt.Println("\n3. Finding the nearest stores with GEOSEARCH:")
+	nearest, err := rdb.GeoSearchLocation(ctx, storesKey, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  customerLon,
+			Latitude:   customerLat,
+			Radius:     5,
+			RadiusUnit: "km",
+			Sort:       "ASC",
+			Count:      3,
+		},
+		WithDist: true,
+	}).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("   Within 5 km of the customer, nearest 3:")
+	for _, s := range nearest {
+		fmt.Printf("     %-14s %.2f km\n", s.Name, s.Dist)
+	}
+	expect("GEOSEARCH", "nearest stores within 5 km", geoNames(nearest), Exactly([]string{"covent-garden", "camden", "shoreditch"}))
+
+	// GEOSEARCH BYBOX - A box is wider than it is tall here, so camden to
+	// the north drops out while shoreditch to the east stays in
+	inBox, err := rdb.GeoSearch(ctx, storesKey, &redis.GeoSearchQuery{
+		Longitude: customerLon,
+		Latitude:  customerLat,
+		BoxWidth:  10,
+		BoxHeight: 6,
+		BoxUnit:   "km",
+		Sort:      "ASC",
+	}).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Within a 10 x 6 km box around the customer: %v\n", inBox)
+	expect("GEOSEARCH", "stores within 10x6 km box", inBox, Exactly([]string{"covent-garden", "shoreditch"}))
+
+	// GEOSEARCH FROMMEMBER - Search around an indexed member
+	neighbours, _ := rdb.GeoSearch(ctx, storesKey, &redis.GeoSearchQuery{
+		Member:     "canary-wharf",
+		Radius:     3,
+		RadiusUnit: "km",
+		Sort:       "ASC",
+	}).Result()
+	fmt.Printf("   Within 3 km of canary-wharf: %v\n", neighbours)
+	expect("GEOSEARCH", "stores near canary-wharf", neighbours, Exactly([]string{"canary-wharf", "greenwich"}))
+
+	// GEOSEARCHSTORE - Save results as a sorted set scored by distance
+	fmt.Println("\n4. Saving search results with GEOSEARCHSTORE:")
+	stored, err := rdb.GeoSearchStore(ctx, storesKey, nearbyKey, &redis.GeoSearchStoreQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  customerLon,
+			Latitude:   customerLat,
+			Radius:     5,
+			RadiusUnit: "km",
+			Sort:       "ASC",
+		},
+		StoreDist: true,
+	}).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   GEOSEARCHSTORE %s ... STOREDIST: %d stores\n", nearbyKey, stored)
+	expect("GEOSEARCHSTORE", "stores saved", stored, Exactly(int64(3)))
+
+	// With STOREDIST the score is the distance, so it reads like any sorted set
+	byDistance, _ := rdb.ZRangeWithScores(ctx, nearbyKey, 0, -1).Result()
+	for _, z := range byDistance {
+		fmt.Printf("     %-14v %.2f km\n", z.Member, z.Score)
+	}
+
+	// Drivers near a rider - Locations keep changing while we search
+	fmt.Println("\n5. Drivers near a rider, with live location updates:")
+	rng := rand.New(rand.NewSource(35))
+	drivers := make([]*redis.GeoLocation, 5)
+	for i := range drivers {
+		drivers[i] = &redis.GeoLocation{
+			Name:      fmt.Sprintf("driver-%d", i+1),
+			Longitude: customerLon + (rng.Float64()-0.5)*0.08,
+			Latitude:  customerLat + (rng.Float64()-0.5)*0.05,
+		}
+	}
+	rdb.GeoAdd(ctx, driversKey, drivers...)
+
+	// driver-1 heads straight for the rider, the others wander about
+	const ticks = 10
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for tick := 1; tick <= ticks; tick++ {
+			time.Sleep(100 * time.Millisecond)
+			for i, d := range drivers {
+				if i == 0 {
+					d.Longitude += (customerLon - d.Longitude) / float64(ticks-tick+1)
+					d.Latitude += (customerLat - d.Latitude) / float64(ticks-tick+1)
+				} else {
+					d.Longitude += (rng.Float64() - 0.5) * 0.004
+					d.Latitude += (rng.Float64() - 0.5) * 0.0025
+				}
+			}
+			// GEOADD on an existing member just moves it
+			rdb.GeoAdd(ctx, driversKey, drivers...)
+		}
+	}()
+
+	rider := redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery{
+			Longitude:  customerLon,
+			Latitude:   customerLat,
+			Radius:     3,
+			RadiusUnit: "km",
+			Sort:       "ASC",
+			Count:      3,
+		},
+		WithDist: true,
+	}
+	var closest []redis.GeoLocation
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		case <-time.After(250 * time.Millisecond):
+		}
+
+		closest, err = rdb.GeoSearchLocation(ctx, driversKey, &rider).Result()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Print("   Nearest drivers within 3 km:")
+		for _, d := range closest {
+			fmt.Printf(" %s (%.2f km)", d.Name, d.Dist)
+		}
+		fmt.Println()
+	}
+	if len(closest) > 0 {
+		fmt.Printf("   Nearest driver once updates stop: %s\n", closest[0].Name)
+		expect("GEOSEARCH", "nearest driver after updates", closest[0].Name, Exactly("driver-1"))
+		expect("GEOSEARCH", "nearest driver distance km", closest[0].Dist, InRange(0, 0.01))
+	}
+
+	// Cleanup
+	fmt.Println("\n6. Cleanup:")
+	rdb.Del(ctx, storesKey, nearbyKey, driversKey)
+	fmt.Println("   Cleaned up geospatial examples ✓")
+}
+
+// geoNames returns the member names of locs in order
+func geoNames(locs []redis.GeoLocation) []string {
+	names := make([]string, len(locs))
+	for i, l := range locs {
+		names[i] = l.Name
+	}
+	return names
+}
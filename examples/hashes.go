@@ -3,6 +3,8 @@ package examples
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -16,19 +18,31 @@ func RunHashesExamples(rdb *redis.Client) {
 
 	// HSET - Set hash field values
 	fmt.Println("1. Creating user profile with HSET:")
-	err := rdb.HSet(ctx, "user:123", map[string]interface{}{
-		"name":     "John Doe",
-		"email":    "john@example.com",
-		"age":      "30",
-		"location": "San Francisco",
-		"role":     "Developer",
-	}).Err()
+
+	// The first profile is the one the walkthrough uses (John Doe unless a
+	// dataset is loaded), any others are stored as profile:<n>
+	profile := profileData[0]
+	err := rdb.HSet(ctx, "user:123", profile).Err()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	fmt.Println("   User profile created ✓")
 
+	var extraProfiles []string
+	if len(profileData) > 1 {
+		start := time.Now()
+		for i, p := range profileData[1:] {
+			key := fmt.Sprintf("profile:%d", i+1)
+			if err := rdb.HSet(ctx, key, p).Err(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			extraProfiles = append(extraProfiles, key)
+		}
+		fmt.Printf("   Stored %d more profiles in %v\n", len(extraProfiles), time.Since(start))
+	}
+
 	// HGET - Get specific field value
 	fmt.Println("\n2. Getting specific fields with HGET:")
 	name, err := rdb.HGet(ctx, "user:123", "name").Result()
@@ -37,6 +51,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Name: %s\n", name)
+	expect("HGET", "name field", name, Exactly(profile["name"]))
 
 	email, err := rdb.HGet(ctx, "user:123", "email").Result()
 	if err != nil {
@@ -44,6 +59,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Email: %s\n", email)
+	expect("HGET", "email field", email, Exactly(profile["email"]))
 
 	// HGETALL - Get all fields and values
 	fmt.Println("\n3. Getting all fields with HGETALL:")
@@ -52,6 +68,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("HGETALL", "complete profile", userProfile, Exactly(profile))
 	fmt.Println("   Complete profile:")
 	for field, value := range userProfile {
 		fmt.Printf("     %s: %s\n", field, value)
@@ -64,6 +81,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("HMGET", "selected fields", fields, Exactly([]interface{}{profile["name"], profile["role"], profile["location"]}))
 	fieldNames := []string{"name", "role", "location"}
 	fmt.Println("   Selected fields:")
 	for i, field := range fieldNames {
@@ -78,6 +96,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Field 'age' exists: %t\n", exists)
+	expect("HEXISTS", "age field exists", exists, Exactly(true))
 
 	exists, err = rdb.HExists(ctx, "user:123", "salary").Result()
 	if err != nil {
@@ -85,6 +104,8 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Field 'salary' exists: %t\n", exists)
+	_, hasSalary := profile["salary"]
+	expect("HEXISTS", "salary field exists", exists, Exactly(hasSalary))
 
 	// HKEYS - Get all field names
 	fmt.Println("\n6. Getting all field names with HKEYS:")
@@ -94,6 +115,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Available fields: %v\n", keys)
+	expect("HKEYS", "available fields", keys, SameMembers(hashFields(profile)...))
 
 	// HVALS - Get all values
 	fmt.Println("\n7. Getting all values with HVALS:")
@@ -103,6 +125,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   All values: %v\n", values)
+	expect("HVALS", "all values", values, SameMembers(hashValues(profile)...))
 
 	// HLEN - Get number of fields
 	fmt.Println("\n8. Getting field count with HLEN:")
@@ -112,6 +135,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Number of fields: %d\n", fieldCount)
+	expect("HLEN", "number of fields", fieldCount, Exactly(int64(len(profile))))
 
 	// HINCRBY - Increment numeric field
 	fmt.Println("\n9. Incrementing numeric fields with HINCRBY:")
@@ -121,6 +145,8 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Age after increment: %d\n", newAge)
+	age, _ := strconv.ParseInt(profile["age"], 10, 64)
+	expect("HINCRBY", "age after increment", newAge, Exactly(age+1))
 
 	// HDEL - Delete specific fields
 	fmt.Println("\n10. Deleting fields with HDEL:")
@@ -130,6 +156,7 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Deleted %d field(s)\n", deleted)
+	expect("HDEL", "fields deleted", deleted, Exactly(int64(1)))
 
 	// Verify deletion
 	remainingFields, err := rdb.HKeys(ctx, "user:123").Result()
@@ -138,6 +165,13 @@ func RunHashesExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Remaining fields: %v\n", remainingFields)
+	var keptFields []string
+	for _, field := range hashFields(profile) {
+		if field != "location" {
+			keptFields = append(keptFields, field)
+		}
+	}
+	expect("HKEYS", "remaining fields", remainingFields, SameMembers(keptFields...))
 
 	// Practical example: Session management
 	fmt.Println("\n11. Practical example - Session management:")
@@ -161,6 +195,13 @@ func RunHashesExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("HGETALL", "session data", sessionData, Exactly(map[string]string{
+		"user_id":    "123",
+		"username":   "johndoe",
+		"login_time": "2024-01-01T10:00:00Z",
+		"ip_address": "192.168.1.1",
+		"user_agent": "Mozilla/5.0...",
+	}))
 	fmt.Println("   Session data:")
 	for k, v := range sessionData {
 		fmt.Printf("     %s: %s\n", k, v)
@@ -168,6 +209,22 @@ func RunHashesExamples(rdb *redis.Client) {
 
 	// Cleanup
 	fmt.Println("\n12. Cleanup:")
-	rdb.Del(ctx, "user:123", sessionID)
+	rdb.Del(ctx, append([]string{"user:123", sessionID}, extraProfiles...)...)
 	fmt.Println("   Cleaned up hash examples ✓")
 }
+
+func hashFields(h map[string]string) []string {
+	fields := make([]string, 0, len(h))
+	for field := range h {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func hashValues(h map[string]string) []string {
+	values := make([]string, 0, len(h))
+	for _, value := range h {
+		values = append(values, value)
+	}
+	return values
+}
@@ -0,0 +1,135 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunHyperLogLogExamples demonstrates unique counting with HyperLogLog
+func RunHyperLogLogExamples(rdb *redis.Client) {
+	fmt.Println("\n HyperLogLog Operations")
+	fmt.Println("=========================")
+
+	ctx := context.Background()
+
+	// PFADD / PFCOUNT - Count unique visitors
+	fmt.Println("1. Counting unique visitors with PFADD and PFCOUNT:")
+	todayKey := "visitors:hll:today"
+	changed, err := rdb.PFAdd(ctx, todayKey, "user:1", "user:2", "user:3", "user:2", "user:1").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   PFADD 5 visits from 3 visitors: registers changed = %d\n", changed)
+
+	// Adding someone already counted leaves the registers untouched
+	changed, _ = rdb.PFAdd(ctx, todayKey, "user:3").Result()
+	fmt.Printf("   PFADD a repeat visitor: registers changed = %d\n", changed)
+	expect("PFADD", "repeat visitor changes nothing", changed, Exactly(int64(0)))
+
+	unique, err := rdb.PFCount(ctx, todayKey).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Unique visitors today: %d\n", unique)
+	expect("PFCOUNT", "unique visitors today", unique, Exactly(int64(3)))
+
+	// PFMERGE - Combine daily counts into a weekly one
+	fmt.Println("\n2. Merging daily counts into a weekly count with PFMERGE:")
+
+	// Each day 300 visitors out of a pool of 1000 show up, so days overlap
+	rng := rand.New(rand.NewSource(7))
+	days := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+	var dailyHLL, dailySets []string
+	for _, day := range days {
+		hllKey, setKey := "visitors:hll:"+day, "visitors:set:"+day
+		visitors := make([]interface{}, 300)
+		for i := range visitors {
+			visitors[i] = fmt.Sprintf("user:%d", rng.Intn(1000))
+		}
+		rdb.PFAdd(ctx, hllKey, visitors...)
+		rdb.SAdd(ctx, setKey, visitors...)
+		dailyHLL = append(dailyHLL, hllKey)
+		dailySets = append(dailySets, setKey)
+
+		estimate, _ := rdb.PFCount(ctx, hllKey).Result()
+		exact, _ := rdb.SCard(ctx, setKey).Result()
+		fmt.Printf("   %s: HLL %d, exact %d\n", day, estimate, exact)
+	}
+
+	weekHLL, weekSet := "visitors:hll:week", "visitors:set:week"
+	err = rdb.PFMerge(ctx, weekHLL, dailyHLL...).Err()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	rdb.SUnionStore(ctx, weekSet, dailySets...)
+
+	weekEstimate, _ := rdb.PFCount(ctx, weekHLL).Result()
+	weekExact, _ := rdb.SCard(ctx, weekSet).Result()
+	fmt.Printf("   Week (PFMERGE): HLL %d, exact %d (SUNIONSTORE), error %.2f%%\n",
+		weekEstimate, weekExact, errorPercent(weekEstimate, weekExact))
+	expect("PFMERGE", "weekly estimate error %", errorPercent(weekEstimate, weekExact), InRange(0, 2))
+
+	// PFCOUNT over several keys counts their union without storing it
+	unionEstimate, _ := rdb.PFCount(ctx, dailyHLL[:2]...).Result()
+	fmt.Printf("   PFCOUNT mon tue (union on the fly): %d\n", unionEstimate)
+
+	// Accuracy and memory as cardinality grows
+	fmt.Println("\n3. Accuracy and memory compared with an exact Set:")
+	fmt.Println("   Cardinality |   PFCOUNT |  Error |  HLL bytes |  Set bytes")
+	growHLL, growSet := "visitors:hll:grow", "visitors:set:grow"
+	added := 0
+	for _, target := range []int{100, 1000, 10000, 100000} {
+		// Add the next batch of distinct IDs in chunks to keep commands small
+		for added < target {
+			n := min(1000, target-added)
+			batch := make([]interface{}, n)
+			for i := range batch {
+				batch[i] = fmt.Sprintf("visitor:%d", added+i)
+			}
+			rdb.PFAdd(ctx, growHLL, batch...)
+			rdb.SAdd(ctx, growSet, batch...)
+			added += n
+		}
+
+		estimate, err := rdb.PFCount(ctx, growHLL).Result()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		exact, _ := rdb.SCard(ctx, growSet).Result()
+
+		// SAMPLES 0 makes MEMORY USAGE inspect every element of the Set
+		hllBytes, err := rdb.MemoryUsage(ctx, growHLL, 0).Result()
+		if err != nil {
+			fmt.Printf("   MEMORY USAGE not available: %v\n", err)
+		}
+		setBytes, _ := rdb.MemoryUsage(ctx, growSet, 0).Result()
+
+		fmt.Printf("   %11d | %9d | %5.2f%% | %10d | %10d\n",
+			exact, estimate, errorPercent(estimate, exact), hllBytes, setBytes)
+		expect("PFCOUNT", fmt.Sprintf("error %% at %d", target), errorPercent(estimate, exact), InRange(0, 2))
+		expect("SCARD", fmt.Sprintf("exact count at %d", target), exact, Exactly(int64(target)))
+	}
+	fmt.Println("   HLL memory is capped at ~12KB (standard error 0.81%) while the Set keeps growing")
+
+	// Cleanup
+	fmt.Println("\n4. Cleanup:")
+	keys := append([]string{todayKey, weekHLL, weekSet, growHLL, growSet}, dailyHLL...)
+	rdb.Del(ctx, append(keys, dailySets...)...)
+	fmt.Println("   Cleaned up HyperLogLog examples ✓")
+}
+
+// errorPercent is how far estimate is from exact, as a percentage
+func errorPercent(estimate, exact int64) float64 {
+	if exact == 0 {
+		return 0
+	}
+	return math.Abs(float64(estimate-exact)) / float64(exact) * 100
+}
@@ -0,0 +1,182 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyspaceEvents enables keyspace (K) and keyevent (E) notifications for
+// generic commands like DEL and EXPIRE (g), string commands ($) and
+// expirations (x)
+const keyspaceEvents = "KEg$x"
+
+// RunKeyspaceNotificationExamples demonstrates keyspace notifications for
+// writes and expirations, instead of polling to notice that a key expired
+func RunKeyspaceNotificationExamples(rdb *redis.Client) {
+	fmt.Println("\n Keyspace Notifications")
+	fmt.Println("=========================")
+
+	ctx := context.Background()
+	db := rdb.Options().DB
+	keyspaceChannel := fmt.Sprintf("__keyspace@%d__:notify:*", db)
+	expiredChannel := fmt.Sprintf("__keyevent@%d__:expired", db)
+	rdb.Del(ctx, "notify:profile", "notify:counter", "notify:session", "notify:lazy", "notify:active")
+
+	// 1. Notifications are off by default, since they cost CPU on every write
+	fmt.Println("1. Enabling notify-keyspace-events:")
+	previous, err := rdb.ConfigGet(ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := rdb.ConfigSet(ctx, "notify-keyspace-events", keyspaceEvents).Err(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer func() {
+		// Leave the server as we found it
+		rdb.ConfigSet(ctx, "notify-keyspace-events", previous["notify-keyspace-events"])
+		fmt.Printf("   Restored notify-keyspace-events to %q\n", previous["notify-keyspace-events"])
+	}()
+	fmt.Printf("   Was %q, now %q\n", previous["notify-keyspace-events"], keyspaceEvents)
+
+	// 2. Keyspace channels are named after the key and carry the event,
+	// keyevent channels are named after the event and carry the key
+	fmt.Println("\n2. Subscribing:")
+	pubsub := rdb.PSubscribe(ctx, keyspaceChannel)
+	defer pubsub.Close()
+	// Wait for both confirmations so no event is missed
+	if err := confirmSubscription(ctx, pubsub, "psubscribe", keyspaceChannel); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := pubsub.Subscribe(ctx, expiredChannel); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := confirmSubscription(ctx, pubsub, "subscribe", expiredChannel); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   PSUBSCRIBE %s\n", keyspaceChannel)
+	fmt.Printf("   SUBSCRIBE %s\n", expiredChannel)
+
+	// Print events live as they arrive and hand them to the checks below
+	start := time.Now()
+	events := make(chan *redis.Message, 100)
+	var printer sync.WaitGroup
+	printer.Add(1)
+	go func() {
+		defer printer.Done()
+		defer close(events)
+		for msg := range pubsub.Channel() {
+			fmt.Printf("   [%6v] %s -> %s\n", time.Since(start).Round(time.Millisecond), msg.Channel, msg.Payload)
+			events <- msg
+		}
+	}()
+	defer func() {
+		pubsub.Close()
+		printer.Wait()
+	}()
+
+	// 3. Writes - each command publishes its event name on the key's channel
+	fmt.Println("\n3. Writes, as seen on the keyspace channels:")
+	rdb.Set(ctx, "notify:profile", "Naim", 0)
+	rdb.Append(ctx, "notify:profile", " Dev")
+	rdb.Incr(ctx, "notify:counter")
+	rdb.Del(ctx, "notify:profile")
+	got := collectEvents(events, 4, time.Second)
+	expect("PSUBSCRIBE", "keyspace events for the writes", got, Exactly([]string{
+		"notify:profile set", "notify:profile append", "notify:counter incrby", "notify:profile del",
+	}))
+
+	// 4. Expirations - the expired event replaces polling with GET
+	fmt.Println("\n4. Expirations, as seen on the expired keyevent channel:")
+	rdb.Set(ctx, "notify:session", "user_data", scaled(2*time.Second))
+	expire(ctx, rdb, "notify:counter", time.Second)
+	fmt.Printf("   notify:counter expires in %v, notify:session in %v\n", scaled(time.Second), scaled(2*time.Second))
+	// Each expiry arrives twice, on the key's channel and on the event's
+	got = collectEvents(events, 7, scaled(2*time.Second)+2*time.Second)
+	expect("SUBSCRIBE", "expiry events", got, SameMembers(
+		"notify:session set", "notify:session expire", "notify:counter expire",
+		"notify:counter expired", "notify:counter expired",
+		"notify:session expired", "notify:session expired",
+	))
+
+	// 5. Expired events fire when Redis actually removes the key, not at the
+	// moment the TTL runs out
+	fmt.Println("\n5. Lazy vs active expiry:")
+	ttl := scaled(500 * time.Millisecond)
+	rdb.Set(ctx, "notify:lazy", "x", ttl)
+	rdb.Set(ctx, "notify:active", "x", ttl)
+	deadline := time.Now().Add(ttl)
+	time.Sleep(time.Until(deadline) + time.Millisecond)
+	rdb.Get(ctx, "notify:lazy") // touching an expired key removes it right away
+	delays := expiryDelays(events, deadline, 2, 2*time.Second)
+	fmt.Printf("   notify:lazy, read just after its TTL: event %v after the TTL\n", delays["notify:lazy"].Round(time.Millisecond))
+	fmt.Printf("   notify:active, never read: event %v after the TTL\n", delays["notify:active"].Round(time.Millisecond))
+	expect("SUBSCRIBE", "expired events for both keys", len(delays), Exactly(2))
+	fmt.Println("   A key that is read after its TTL is expired lazily, on access. Otherwise")
+	fmt.Println("   the active expire cycle finds it by sampling keys with a TTL, hz times a")
+	fmt.Println("   second, so the event can come noticeably late when many keys have TTLs.")
+
+	// Cleanup
+	rdb.Del(ctx, "notify:profile", "notify:counter", "notify:session", "notify:lazy", "notify:active")
+	fmt.Println("\n6. Cleanup: Cleaned up keyspace notification examples ✓")
+}
+
+// collectEvents waits up to timeout for n events, returned as "key event"
+func collectEvents(events <-chan *redis.Message, n int, timeout time.Duration) []string {
+	var got []string
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(got) < n {
+		select {
+		case msg, ok := <-events:
+			if !ok {
+				return got
+			}
+			got = append(got, eventString(msg))
+		case <-timer.C:
+			return got
+		}
+	}
+	return got
+}
+
+// eventString formats keyspace and keyevent messages alike as "key event"
+func eventString(msg *redis.Message) string {
+	channel := msg.Channel[strings.Index(msg.Channel, "__:")+3:]
+	if strings.HasPrefix(msg.Channel, "__keyevent@") {
+		return msg.Payload + " " + channel
+	}
+	return channel + " " + msg.Payload
+}
+
+// expiryDelays waits up to timeout for n expired keyevents and returns how
+// long after deadline each key's event arrived
+func expiryDelays(events <-chan *redis.Message, deadline time.Time, n int, timeout time.Duration) map[string]time.Duration {
+	delays := make(map[string]time.Duration)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(delays) < n {
+		select {
+		case msg, ok := <-events:
+			if !ok {
+				return delays
+			}
+			// Skip the keyspace events for the same keys
+			if strings.HasPrefix(msg.Channel, "__keyevent@") {
+				delays[msg.Payload] = time.Since(deadline)
+			}
+		case <-timer.C:
+			return delays
+		}
+	}
+	return delays
+}
@@ -27,6 +27,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   RPUSH task_queue task1 task2 task3: length = %d\n", length)
+	expect("RPUSH", "queue length after RPUSH", length, Exactly(int64(3)))
 
 	// Add urgent task to the left (beginning) of the queue
 	length, err = rdb.LPush(ctx, listKey, "urgent_task").Result()
@@ -35,6 +36,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   LPUSH task_queue urgent_task: length = %d\n", length)
+	expect("LPUSH", "queue length after LPUSH", length, Exactly(int64(4)))
 
 	// LRANGE - Get elements from the list
 	fmt.Println("\n2. Viewing list contents with LRANGE:")
@@ -44,6 +46,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Current queue: %v\n", tasks)
+	expect("LRANGE", "current queue", tasks, Exactly([]string{"urgent_task", "task1", "task2", "task3"}))
 
 	// Get first 2 elements
 	firstTwo, err := rdb.LRange(ctx, listKey, 0, 1).Result()
@@ -52,6 +55,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   First 2 tasks: %v\n", firstTwo)
+	expect("LRANGE", "first 2 tasks", firstTwo, Exactly([]string{"urgent_task", "task1"}))
 
 	// LPOP/RPOP - Remove and return elements
 	fmt.Println("\n3. Processing tasks with LPOP and RPOP:")
@@ -63,6 +67,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   LPOP (processed): %s\n", task)
+	expect("LPOP", "processed task", task, Exactly("urgent_task"))
 
 	// Check remaining tasks
 	remaining, err := rdb.LRange(ctx, listKey, 0, -1).Result()
@@ -71,6 +76,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Remaining tasks: %v\n", remaining)
+	expect("LRANGE", "remaining tasks", remaining, Exactly([]string{"task1", "task2", "task3"}))
 
 	// LLEN - Get list length
 	fmt.Println("\n4. Checking queue size with LLEN:")
@@ -80,6 +86,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Queue size: %d\n", queueSize)
+	expect("LLEN", "queue size", queueSize, Exactly(int64(3)))
 
 	// LINDEX - Get element at specific index
 	fmt.Println("\n5. Getting specific elements with LINDEX:")
@@ -89,6 +96,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   First task (index 0): %s\n", firstTask)
+	expect("LINDEX", "first task", firstTask, Exactly("task1"))
 
 	lastTask, err := rdb.LIndex(ctx, listKey, -1).Result()
 	if err != nil {
@@ -96,6 +104,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Last task (index -1): %s\n", lastTask)
+	expect("LINDEX", "last task", lastTask, Exactly("task3"))
 
 	// LSET - Set element at specific index
 	fmt.Println("\n6. Updating elements with LSET:")
@@ -112,6 +121,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Updated queue: %v\n", updated)
+	expect("LSET", "updated queue", updated, Exactly([]string{"updated_task1", "task2", "task3"}))
 
 	// LREM - Remove elements
 	fmt.Println("\n7. Removing specific elements with LREM:")
@@ -126,6 +136,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   LREM task_queue 2 'duplicate': removed %d elements\n", removed)
+	expect("LREM", "removed duplicates", removed, Exactly(int64(2)))
 
 	afterRemoval, err := rdb.LRange(ctx, listKey, 0, -1).Result()
 	if err != nil {
@@ -133,6 +144,7 @@ func RunListExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   After removal: %v\n", afterRemoval)
+	expect("LRANGE", "queue after removal", afterRemoval, Exactly([]string{"updated_task1", "task2", "task3", "unique"}))
 
 	// Practical example: Activity feed
 	fmt.Println("\n8. Practical example - Activity feed:")
@@ -157,6 +169,11 @@ func RunListExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("LRANGE", "recent activities", recentActivities, Exactly([]string{
+		"User shared an article",
+		"User liked a post",
+		"User posted a comment",
+	}))
 	fmt.Println("   Recent activities:")
 	for i, activity := range recentActivities {
 		fmt.Printf("     %d. %s\n", i+1, activity)
@@ -179,6 +196,7 @@ func RunListExamples(rdb *redis.Client) {
 
 	// Pop operations (LIFO order)
 	fmt.Println("   Popping from stack:")
+	var popped []string
 	for i := 0; i < 3; i++ {
 		op, err := rdb.LPop(ctx, stackKey).Result()
 		if err == redis.Nil {
@@ -188,7 +206,9 @@ func RunListExamples(rdb *redis.Client) {
 			break
 		}
 		fmt.Printf("     Popped: %s\n", op)
+		popped = append(popped, op)
 	}
+	expect("LPOP", "stack pop order", popped, Exactly([]string{"operation3", "operation2", "operation1"}))
 
 	// Cleanup
 	fmt.Println("\n10. Cleanup:")
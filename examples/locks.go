@@ -0,0 +1,150 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"redis-playground/lock"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fencedStore stands in for a resource that checks fencing tokens, refusing
+// writes that carry a token older than one it has already seen
+type fencedStore struct {
+	mu        sync.Mutex
+	lastFence int64
+	writes    []string
+}
+
+func (s *fencedStore) write(fence int64, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if fence < s.lastFence {
+		return fmt.Errorf("stale fencing token %d, already saw %d", fence, s.lastFence)
+	}
+	s.lastFence = fence
+	s.writes = append(s.writes, value)
+	return nil
+}
+
+// RunLockExamples demonstrates the lock package under contention and
+// across an expired lease
+func RunLockExamples(rdb *redis.Client) {
+	fmt.Println("\n Distributed Locks")
+	fmt.Println("====================")
+
+	ctx := context.Background()
+	name := "report"
+	rdb.Del(ctx, "lock:"+name, "lock:"+name+":fence")
+
+	// Lease lengths and pauses are multiples of tick, shorter in fast mode
+	tick := scaledAtLeast(100*time.Millisecond, 10*time.Millisecond)
+
+	// Two goroutines contend - Acquire blocks with backoff until it wins
+	fmt.Println("1. Two workers contending for one lock:")
+	locker := lock.New(rdb, lock.Options{TTL: 10 * tick})
+	store := &fencedStore{}
+	var wg sync.WaitGroup
+	for _, worker := range []string{"worker-a", "worker-b"} {
+		wg.Add(1)
+		go func(worker string) {
+			defer wg.Done()
+			start := time.Now()
+			lk, err := locker.Acquire(ctx, name)
+			if err != nil {
+				fmt.Printf("   %s: %v\n", worker, err)
+				return
+			}
+			fmt.Printf("   %s acquired after %v, fencing token %d\n", worker, time.Since(start).Round(time.Millisecond), lk.Fence())
+
+			time.Sleep(2 * tick) // the protected work
+			store.write(lk.Fence(), worker)
+
+			if err := lk.Release(ctx); err != nil {
+				fmt.Printf("   %s release: %v\n", worker, err)
+				return
+			}
+			fmt.Printf("   %s released\n", worker)
+		}(worker)
+	}
+	wg.Wait()
+	expect("SET NX PX", "both workers ran in turn", len(store.writes), Exactly(2))
+
+	// A held lock refuses a second TryAcquire
+	held, err := locker.TryAcquire(ctx, name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	_, err = locker.TryAcquire(ctx, name)
+	fmt.Printf("   TryAcquire while it is held: %v\n", errString(err))
+	expect("SET NX PX", "second holder refused", err, Exactly(lock.ErrNotAcquired))
+	held.Release(ctx)
+
+	// The watchdog keeps renewing the lease while work runs longer than the TTL
+	fmt.Println("\n2. The watchdog renews the lease during long work:")
+	short := lock.New(rdb, lock.Options{TTL: 3 * tick})
+	lk, err := short.Acquire(ctx, name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	time.Sleep(10 * tick)
+	ttl, _ := rdb.PTTL(ctx, "lock:"+name).Result()
+	fmt.Printf("   After %v of work with a %v TTL the lock still has %v left\n", 10*tick, 3*tick, ttl.Round(time.Millisecond))
+	expect("PEXPIRE", "lease renewed by watchdog", ttl, Between(time.Millisecond, 3*tick))
+	err = lk.Release(ctx)
+	fmt.Printf("   Release: %v\n", errString(err))
+
+	// Without renewal a pause longer than the TTL (GC, swapping, a stalled VM)
+	// lets someone else in while the first holder still thinks it owns the lock
+	fmt.Println("\n3. A lease that expires during a pause:")
+	noRenew := lock.New(rdb, lock.Options{TTL: 2 * tick, DisableRenew: true})
+	paused, err := noRenew.Acquire(ctx, name)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   worker-a acquired with fencing token %d, then pauses for %v\n", paused.Fence(), 4*tick)
+
+	time.Sleep(3 * tick)
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	next, err := noRenew.Acquire(waitCtx, name)
+	cancel()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   The lease expired, worker-b acquired with fencing token %d\n", next.Fence())
+	store.write(next.Fence(), "worker-b")
+	// Well within worker-b's own lease
+	time.Sleep(tick)
+
+	// worker-a wakes up and carries on as if nothing happened
+	err = store.write(paused.Fence(), "worker-a")
+	fmt.Printf("   worker-a resumes and writes: %v\n", errString(err))
+	expect("INCR", "stale fencing token rejected", err != nil, Exactly(true))
+
+	err = paused.Release(ctx)
+	fmt.Printf("   worker-a releases: %v (worker-b's lock is left alone)\n", errString(err))
+	expect("EVALSHA", "release after expiry", err, Exactly(lock.ErrNotHeld))
+
+	holder, _ := rdb.Get(ctx, "lock:"+name).Result()
+	expect("GET", "lock still held by worker-b", holder, Exactly(next.Token()))
+	next.Release(ctx)
+
+	// Cleanup
+	fmt.Println("\n4. Cleanup:")
+	rdb.Del(ctx, "lock:"+name, "lock:"+name+":fence")
+	fmt.Println("   Cleaned up lock examples ✓")
+}
+
+// errString prints a nil error as "ok"
+func errString(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return err.Error()
+}
@@ -0,0 +1,222 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// benchBatch is how many operations share a round trip when batching
+const benchBatch = 100
+
+var (
+	benchOps     = 1000
+	benchLatency time.Duration
+)
+
+// SetBenchmarkOptions sets how many operations the pipelining benchmark
+// performs per mode, and the latency it adds to every round trip to mimic a
+// remote server
+func SetBenchmarkOptions(ops int, latency time.Duration) {
+	if ops > 0 {
+		benchOps = ops
+	}
+	benchLatency = latency
+}
+
+// latencyHook delays every round trip, a pipeline counts as one
+type latencyHook struct {
+	delay time.Duration
+}
+
+func (h latencyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h latencyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		time.Sleep(h.delay)
+		return next(ctx, cmd)
+	}
+}
+
+func (h latencyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		time.Sleep(h.delay)
+		return next(ctx, cmds)
+	}
+}
+
+// benchWorkload is one kind of operation done one at a time or many at once
+type benchWorkload struct {
+	name     string
+	single   func(c redis.Cmdable, member string) redis.Cmder
+	variadic func(c redis.Cmdable, members []interface{}) redis.Cmder
+}
+
+// benchResult is the outcome of one workload in one mode
+type benchResult struct {
+	mode       string
+	roundTrips []time.Duration
+	total      time.Duration
+}
+
+// RunPipeliningExamples compares round trips for individual, pipelined,
+// transactional and variadic commands
+func RunPipeliningExamples(rdb *redis.Client) {
+	fmt.Println("\n Pipelining and Round Trips")
+	fmt.Println("=============================")
+
+	ctx := context.Background()
+	key := "bench:members"
+
+	// A separate client carries the artificial latency, so rdb is untouched
+	client := rdb
+	if benchLatency > 0 {
+		opts := *rdb.Options()
+		client = newClient(&opts)
+		client.AddHook(latencyHook{delay: benchLatency})
+		defer client.Close()
+	}
+	fmt.Printf("   %d operations per mode, batches of %d, added latency per round trip %v\n",
+		benchOps, benchBatch, benchLatency)
+	fmt.Println("   (change with -bench-ops and -bench-latency)")
+
+	members := make([]string, benchOps)
+	for i := range members {
+		members[i] = "member:" + strconv.Itoa(i)
+	}
+
+	// The same shapes as the SADD loop in the set examples and the
+	// per-article SISMEMBER checks
+	workloads := []benchWorkload{
+		{
+			name: "SADD",
+			single: func(c redis.Cmdable, m string) redis.Cmder {
+				return c.SAdd(ctx, key, m)
+			},
+			variadic: func(c redis.Cmdable, ms []interface{}) redis.Cmder {
+				return c.SAdd(ctx, key, ms...)
+			},
+		},
+		{
+			name: "SISMEMBER",
+			single: func(c redis.Cmdable, m string) redis.Cmder {
+				return c.SIsMember(ctx, key, m)
+			},
+			variadic: func(c redis.Cmdable, ms []interface{}) redis.Cmder {
+				return c.SMIsMember(ctx, key, ms...)
+			},
+		},
+	}
+
+	for i, w := range workloads {
+		fmt.Printf("\n%d. %s x %d:\n", i+1, w.name, benchOps)
+		fmt.Println("   Mode          | Round trips |    ops/sec |      p50 |      p95 |      p99")
+
+		var results []benchResult
+		for _, mode := range []string{"individual", "pipelined", "txpipelined", "variadic"} {
+			// Writes start from an empty set each time, reads need it filled
+			if w.name == "SADD" {
+				rdb.Del(ctx, key)
+			}
+
+			res, err := runBenchMode(ctx, client, mode, w, members)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			results = append(results, res)
+			printBenchResult(res)
+
+			if w.name == "SADD" {
+				count, _ := rdb.SCard(ctx, key).Result()
+				expect(w.name, mode+" members added", count, Exactly(int64(benchOps)))
+			}
+		}
+		expect(w.name, "pipelined round trips", len(results[1].roundTrips), Exactly((benchOps+benchBatch-1)/benchBatch))
+
+		speedup := results[0].total.Seconds() / results[1].total.Seconds()
+		fmt.Printf("   Pipelining was %.1fx faster than individual calls\n", speedup)
+	}
+
+	fmt.Println("\n   Each round trip pays the network latency once, so batching N")
+	fmt.Println("   operations divides that cost by N. TxPipelined adds MULTI/EXEC")
+	fmt.Println("   and atomicity, variadic commands also save per-command overhead.")
+
+	// Cleanup
+	fmt.Printf("\n%d. Cleanup:\n", len(workloads)+1)
+	rdb.Del(ctx, key)
+	fmt.Println("   Cleaned up pipelining examples ✓")
+}
+
+// runBenchMode performs w once per member, using one round trip per member
+// in individual mode and one per batch otherwise
+func runBenchMode(ctx context.Context, c *redis.Client, mode string, w benchWorkload, members []string) (benchResult, error) {
+	res := benchResult{mode: mode}
+	start := time.Now()
+
+	for first := 0; first < len(members); {
+		size := benchBatch
+		if mode == "individual" {
+			size = 1
+		}
+		batch := members[first:min(first+size, len(members))]
+		first += len(batch)
+
+		t := time.Now()
+		var err error
+		switch mode {
+		case "individual":
+			err = w.single(c, batch[0]).Err()
+		case "pipelined":
+			_, err = c.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, m := range batch {
+					w.single(pipe, m)
+				}
+				return nil
+			})
+		case "txpipelined":
+			_, err = c.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, m := range batch {
+					w.single(pipe, m)
+				}
+				return nil
+			})
+		case "variadic":
+			args := make([]interface{}, len(batch))
+			for i, m := range batch {
+				args[i] = m
+			}
+			err = w.variadic(c, args).Err()
+		}
+		if err != nil {
+			return res, err
+		}
+		res.roundTrips = append(res.roundTrips, time.Since(t))
+	}
+
+	res.total = time.Since(start)
+	return res, nil
+}
+
+func printBenchResult(res benchResult) {
+	sorted := append([]time.Duration(nil), res.roundTrips...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	fmt.Printf("   %-13s | %11d | %10.0f | %8v | %8v | %8v\n",
+		res.mode, len(res.roundTrips), float64(benchOps)/res.total.Seconds(),
+		percentile(sorted, 50), percentile(sorted, 95), percentile(sorted, 99))
+}
+
+// percentile returns the p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := (len(sorted)*p+99)/100 - 1
+	return sorted[max(i, 0)].Round(time.Microsecond)
+}
@@ -3,6 +3,7 @@ package examples
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -21,23 +22,29 @@ func RunPubSub(rdb *redis.Client) {
 
 	pubsub := rdb.Subscribe(ctx, channel)
 	defer pubsub.Close()
+	// Wait for the confirmation, anything published before it is lost
+	if err := confirmSubscription(ctx, pubsub, "subscribe", channel); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
 
 	// Start subscriber
 	done := make(chan struct{})
+	var received []string
 	go func() {
+		defer close(done)
 		for i := 0; i < 3; i++ {
 			msg, err := pubsub.ReceiveMessage(ctx)
 			if err != nil {
 				fmt.Println("   Subscriber error:", err)
-				continue
+				return
 			}
 			fmt.Printf("   Subscriber received: %s\n", msg.Payload)
+			received = append(received, msg.Payload)
 		}
-		close(done)
 	}()
 
 	// Publisher
-	time.Sleep(200 * time.Millisecond)
 	for i := 1; i <= 3; i++ {
 		payload := fmt.Sprintf("Hello %d from publisher!", i)
 		rdb.Publish(ctx, channel, payload)
@@ -45,21 +52,192 @@ func RunPubSub(rdb *redis.Client) {
 		time.Sleep(100 * time.Millisecond)
 	}
 	<-done
+	expect("SUBSCRIBE", "messages received in order", received, Exactly([]string{
+		"Hello 1 from publisher!",
+		"Hello 2 from publisher!",
+		"Hello 3 from publisher!",
+	}))
 
 	// Practical example: Real-time notifications
 	fmt.Println("\n2. Practical example - Real-time notification system:")
 	notifyChan := "notifications"
 	notifyPubSub := rdb.Subscribe(ctx, notifyChan)
 	defer notifyPubSub.Close()
+	if err := confirmSubscription(ctx, notifyPubSub, "subscribe", notifyChan); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	// The receiver gives up after a timeout, so it cannot outlive the example
+	notified := make(chan string, 1)
 	go func() {
-		msg, err := notifyPubSub.ReceiveMessage(ctx)
-		if err == nil {
-			fmt.Printf("   Notification received: %s\n", msg.Payload)
+		recvCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		msg, err := notifyPubSub.ReceiveMessage(recvCtx)
+		if err != nil {
+			close(notified)
+			return
 		}
+		notified <- msg.Payload
 	}()
 	rdb.Publish(ctx, notifyChan, "You have a new follower!")
-	time.Sleep(200 * time.Millisecond)
+	notification, ok := <-notified
+	if ok {
+		fmt.Printf("   Notification received: %s\n", notification)
+	} else {
+		fmt.Println("   No notification received")
+	}
+	expect("PUBLISH", "notification payload", notification, Exactly("You have a new follower!"))
+
+	// PSUBSCRIBE - Glob patterns match channels, including ones created later
+	fmt.Println("\n3. Pattern subscriptions with PSUBSCRIBE chat:*:")
+	patternSub := rdb.PSubscribe(ctx, "chat:*")
+	defer patternSub.Close()
+	if err := confirmSubscription(ctx, patternSub, "psubscribe", "chat:*"); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, ch := range []string{"chat:room1", "chat:room2", "news:sports", "chat:lobby"} {
+		rdb.Publish(ctx, ch, "hi from "+ch)
+	}
+	var matched []string
+	for i := 0; i < 3; i++ {
+		recvCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		msg, err := patternSub.ReceiveMessage(recvCtx)
+		cancel()
+		if err != nil {
+			fmt.Printf("   Receive error: %v\n", err)
+			break
+		}
+		fmt.Printf("   pattern %s matched %s: %s\n", msg.Pattern, msg.Channel, msg.Payload)
+		matched = append(matched, msg.Channel)
+	}
+	fmt.Println("   news:sports did not match and was not delivered")
+	expect("PSUBSCRIBE", "channels matched by chat:*", matched, Exactly([]string{"chat:room1", "chat:room2", "chat:lobby"}))
+
+	// PUBSUB - Introspect active channels and subscriber counts
+	fmt.Println("\n4. Introspection with PUBSUB:")
+	channels, err := rdb.PubSubChannels(ctx, "chat:*").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   PUBSUB CHANNELS chat:* -> %v\n", channels)
+	expect("PUBSUB CHANNELS", "active chat channels", channels, SameMembers("chat:room1"))
+
+	numSub, err := rdb.PubSubNumSub(ctx, "chat:room1", "chat:room2", notifyChan).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   PUBSUB NUMSUB -> chat:room1=%d chat:room2=%d %s=%d (pattern subscribers not counted)\n",
+		numSub["chat:room1"], numSub["chat:room2"], notifyChan, numSub[notifyChan])
+	expect("PUBSUB NUMSUB", "subscribers of chat:room1", numSub["chat:room1"], Exactly(int64(1)))
+
+	numPat, err := rdb.PubSubNumPat(ctx).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   PUBSUB NUMPAT -> %d pattern subscription(s) on the server\n", numPat)
+	expect("PUBSUB NUMPAT", "pattern subscriptions", numPat, InRange(1, 1e6))
+
+	// Channel() - Messages delivered on a Go channel, with a health check
+	// that pings an idle connection and resubscribes if it was lost
+	fmt.Println("\n5. Go channel API with health checks:")
+	if !runChannelSubscriber(ctx, rdb) {
+		return
+	}
+
+	// SSUBSCRIBE/SPUBLISH - Sharded channels stay on the shard that owns the
+	// channel's slot instead of being broadcast across a whole cluster
+	fmt.Println("\n6. Sharded pub/sub with SSUBSCRIBE and SPUBLISH:")
+	runShardedPubSub(ctx, rdb)
+
+	// The deferred Close calls end every subscription and its connection
+	fmt.Println("\n7. Pub/Sub demo complete ✓")
+}
+
+// runChannelSubscriber consumes messages through PubSub.Channel from a
+// goroutine that ends when the subscription is closed, returning false if
+// the example had to stop early
+func runChannelSubscriber(ctx context.Context, rdb *redis.Client) bool {
+	channel := "alerts"
+	sub := rdb.Subscribe(ctx, channel)
+	if err := confirmSubscription(ctx, sub, "subscribe", channel); err != nil {
+		sub.Close()
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	healthCheck := 300 * time.Millisecond
+	ch := sub.Channel(
+		redis.WithChannelHealthCheckInterval(healthCheck),
+		redis.WithChannelSize(10),
+	)
+
+	var wg sync.WaitGroup
+	var received []string
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// The channel is closed by sub.Close, which ends the loop
+		for msg := range ch {
+			fmt.Printf("   Received on %s: %s\n", msg.Channel, msg.Payload)
+			received = append(received, msg.Payload)
+		}
+	}()
+
+	rdb.Publish(ctx, channel, "disk 80% full")
+	// Stay idle past a few health checks, the connection keeps being pinged
+	time.Sleep(3 * healthCheck)
+	rdb.Publish(ctx, channel, "disk 95% full")
+	time.Sleep(100 * time.Millisecond)
+
+	sub.Close()
+	wg.Wait()
+	fmt.Printf("   Idle for %v with a %v health check, then closed: the reader goroutine exited\n", 3*healthCheck, healthCheck)
+	expect("SUBSCRIBE", "messages through Channel()", received, Exactly([]string{"disk 80% full", "disk 95% full"}))
+	return true
+}
+
+// runShardedPubSub demonstrates sharded channels, which need Redis 7.0
+func runShardedPubSub(ctx context.Context, rdb *redis.Client) {
+	version, major, err := serverMajorVersion(ctx, rdb)
+	if err != nil {
+		fmt.Printf("   Could not read the server version (%v), skipping\n", err)
+		return
+	}
+	if major < 7 {
+		fmt.Printf("   Server is version %s, sharded pub/sub needs 7.0 or newer, skipping\n", version)
+		return
+	}
+
+	// Channels with the same hash tag share a slot, and so a shard
+	channel := "orders:{eu}:created"
+	sub := rdb.SSubscribe(ctx, channel)
+	defer sub.Close()
+	if err := confirmSubscription(ctx, sub, "ssubscribe", channel); err != nil {
+		fmt.Printf("   Error: %v\n", err)
+		return
+	}
+
+	receivers, err := rdb.SPublish(ctx, channel, "order 1001").Result()
+	if err != nil {
+		fmt.Printf("   Error: %v\n", err)
+		return
+	}
+	recvCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	msg, err := sub.ReceiveMessage(recvCtx)
+	if err != nil {
+		fmt.Printf("   Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   SPUBLISH %s -> %d receiver(s), got %q\n", channel, receivers, msg.Payload)
+	expect("SSUBSCRIBE", "sharded message", msg.Payload, Exactly("order 1001"))
 
-	// Cleanup (no actual cleanup needed for pub/sub channels)
-	fmt.Println("\n3. Pub/Sub demo complete ✓")
+	shardChannels, _ := rdb.PubSubShardChannels(ctx, "orders:*").Result()
+	shardNumSub, _ := rdb.PubSubShardNumSub(ctx, channel).Result()
+	fmt.Printf("   PUBSUB SHARDCHANNELS orders:* -> %v, SHARDNUMSUB -> %d\n", shardChannels, shardNumSub[channel])
+	expect("PUBSUB SHARDNUMSUB", "sharded subscribers", shardNumSub[channel], Exactly(int64(1)))
+	fmt.Println("   Sharded channels do not show up in PUBSUB CHANNELS and PSUBSCRIBE has no sharded form")
 }
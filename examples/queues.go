@@ -0,0 +1,160 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"redis-playground/queue"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunReliableQueueExamples demonstrates the queue package redelivering the
+// task of a worker that dies mid-task
+func RunReliableQueueExamples(rdb *redis.Client) {
+	fmt.Println("\n Reliable Work Queue")
+	fmt.Println("======================")
+
+	ctx := context.Background()
+
+	// 1. The problem - once LPOP returns, the task exists only in the
+	// worker's memory
+	fmt.Println("1. A plain LPOP queue loses tasks when the worker dies:")
+	naive := "queue:naive"
+	rdb.Del(ctx, naive)
+	rdb.RPush(ctx, naive, "task-1", "task-2")
+	task, err := rdb.LPop(ctx, naive).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	// ... and the worker crashes here
+	left, _ := rdb.LRange(ctx, naive, 0, -1).Result()
+	fmt.Printf("   Worker popped %s and crashed, the queue now holds %v: %s is gone\n", task, left, task)
+	expect("LPOP", "tasks left after a crash", left, Exactly([]string{"task-2"}))
+	rdb.Del(ctx, naive)
+
+	// 2. BLMOVE into a processing list - the task is always in Redis until
+	// it is acknowledged
+	fmt.Println("\n2. Reliable queue, worker-2 is killed mid-task:")
+	q := queue.New(rdb, "demo", queue.Options{
+		VisibilityTimeout: time.Second,
+		Heartbeat:         200 * time.Millisecond,
+		ReapInterval:      250 * time.Millisecond,
+	})
+	q.Delete(ctx)
+
+	tasks := []string{"task-1", "task-2", "task-3", "task-4", "task-5", "task-6"}
+	var mu sync.Mutex
+	deliveries := make(map[string]int)
+	var killed string
+	completed := make(chan string, len(tasks))
+	// The reaper and the surviving workers stop once every task is done
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, id := range []string{"worker-1", "worker-2", "worker-3"} {
+		// Each worker gets its own context, so one can be killed alone
+		workerCtx, kill := context.WithCancel(runCtx)
+		w := q.Worker(id)
+		handler := func(ctx context.Context, task string) error {
+			mu.Lock()
+			deliveries[task]++
+			attempt := deliveries[task]
+			mu.Unlock()
+			if attempt > 1 {
+				fmt.Printf("   %s processing %s (redelivered)\n", w.ID(), task)
+			} else {
+				fmt.Printf("   %s processing %s\n", w.ID(), task)
+			}
+
+			// worker-2 dies during its first task
+			mu.Lock()
+			die := w.ID() == "worker-2" && killed == ""
+			if die {
+				killed = task
+			}
+			mu.Unlock()
+			if die {
+				fmt.Printf("   %s killed while processing %s\n", w.ID(), task)
+				kill()
+				return ctx.Err()
+			}
+			time.Sleep(100 * time.Millisecond) // the actual work
+			completed <- task
+			return nil
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer kill()
+			if err := w.Run(workerCtx, handler); err != nil {
+				fmt.Printf("   %s: %v\n", w.ID(), err)
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.RunReaper(runCtx, func(n int) {
+			fmt.Printf("   Reaper: a heartbeat expired, %d task(s) back on the queue\n", n)
+		})
+	}()
+
+	start := time.Now()
+	if err := q.Push(ctx, tasks...); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	var done []string
+	timeout := time.After(10 * time.Second)
+wait:
+	for len(done) < len(tasks) {
+		select {
+		case task := <-completed:
+			done = append(done, task)
+		case <-timeout:
+			fmt.Println("   Timed out waiting for tasks")
+			break wait
+		}
+	}
+	fmt.Printf("   All tasks done after %v\n", time.Since(start).Round(time.Millisecond))
+
+	// 3. Metrics
+	fmt.Println("\n3. Metrics:")
+	depth, err := q.Depth(ctx)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	stats := q.Stats()
+	fmt.Printf("   pushed %d, delivered %d, acked %d, failed %d, redelivered %d, dead workers reaped %d\n",
+		stats.Pushed, stats.Delivered, stats.Acked, stats.Failed, stats.Redelivered, stats.Reaped)
+	fmt.Printf("   pending %d, processing %v, failed %d\n", depth.Pending, depth.Processing, depth.Failed)
+	mu.Lock()
+	fmt.Printf("   %s was delivered %d times, every other task once\n", killed, deliveries[killed])
+	expect("BLMOVE", "deliveries of the killed task", deliveries[killed], Exactly(2))
+	mu.Unlock()
+	sort.Strings(done)
+	expect("LREM", "completed tasks", done, Exactly(tasks))
+	expect("EVALSHA", "tasks redelivered by the reaper", stats.Redelivered, Exactly(int64(1)))
+
+	// Shut down cleanly: stop the survivors, then hand back anything they hold
+	stop()
+	wg.Wait()
+	for _, id := range []string{"worker-1", "worker-3"} {
+		if err := q.Worker(id).Stop(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Printf("   Stopping %s: %v\n", id, err)
+		}
+	}
+
+	// Cleanup
+	q.Delete(ctx)
+	fmt.Println("\n4. Cleanup: Cleaned up queue examples ✓")
+}
@@ -0,0 +1,157 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"redis-playground/ratelimit"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunRateLimitExamples fires bursts at each rate limiter and charts which
+// requests were accepted
+func RunRateLimitExamples(rdb *redis.Client) {
+	fmt.Println("\n Rate Limiting")
+	fmt.Println("================")
+
+	ctx := context.Background()
+	cleanupRateLimitKeys(ctx, rdb)
+
+	// Every limiter allows 5 requests per unit, with bursts of 5. The unit
+	// is a second, shorter in fast mode.
+	unit := scaledAtLeast(time.Second, 200*time.Millisecond)
+	limiters := []struct {
+		name string
+		ratelimit.Limiter
+	}{
+		{"fixed window", ratelimit.NewFixedWindow(rdb, 5, unit)},
+		{"sliding log", ratelimit.NewSlidingLog(rdb, 5, unit)},
+		{"token bucket", ratelimit.NewTokenBucket(rdb, 5/unit.Seconds(), 5)},
+		{"gcra", ratelimit.NewGCRA(rdb, 5, unit, 5)},
+	}
+
+	// fire sends count single requests to every limiter and records the
+	// pattern as █ for accepted and · for rejected
+	fire := func(key string, count int, charts []string) ([]string, []int, error) {
+		accepted := make([]int, len(limiters))
+		for i, l := range limiters {
+			var row strings.Builder
+			for j := 0; j < count; j++ {
+				res, err := l.Allow(ctx, key, 1)
+				if err != nil {
+					return nil, nil, err
+				}
+				if res.Allowed {
+					accepted[i]++
+					row.WriteString("█")
+				} else {
+					row.WriteString("·")
+				}
+			}
+			charts[i] += row.String() + " "
+		}
+		return charts, accepted, nil
+	}
+
+	// Bursts - 8 requests every 2/5 of a unit
+	gap := unit * 2 / 5
+	fmt.Printf("1. Four bursts of 8 requests, %v apart (█ accepted, · rejected):\n", gap)
+	charts := make([]string, len(limiters))
+	totals := make([]int, len(limiters))
+	for burst := 0; burst < 4; burst++ {
+		if burst > 0 {
+			time.Sleep(gap)
+		}
+		var accepted []int
+		var err error
+		charts, accepted, err = fire("playground-burst", 8, charts)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		for i, n := range accepted {
+			totals[i] += n
+		}
+	}
+	for i, l := range limiters {
+		fmt.Printf("   %-13s %s %2d/32\n", l.name, charts[i], totals[i])
+	}
+	fmt.Printf("   The bucket and GCRA refill 2 requests per %v, the windows reset all at once\n", gap)
+	expect("EVALSHA", "gcra first burst accepted", strings.Count(strings.Fields(charts[3])[0], "█"), Exactly(5))
+
+	// Window boundary - 5 requests just before a window ends, 5 just after
+	margin := unit / 20
+	fmt.Printf("\n2. 5 requests %v before a window boundary and 5 requests %v after:\n", margin, margin)
+	edge := time.Now().Truncate(unit).Add(unit)
+	if time.Until(edge) < 2*margin {
+		edge = edge.Add(unit)
+	}
+	time.Sleep(time.Until(edge) - margin)
+	charts = make([]string, len(limiters))
+	charts, before, err := fire("playground-edge", 5, charts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	time.Sleep(time.Until(edge) + margin)
+	charts, after, err := fire("playground-edge", 5, charts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	accepted := make([]int, len(limiters))
+	for i, l := range limiters {
+		accepted[i] = before[i] + after[i]
+		fmt.Printf("   %-13s %s %2d/10 within %v\n", l.name, charts[i], accepted[i], 2*margin)
+	}
+	fmt.Println("   The fixed window lets twice the limit through across the boundary")
+	expect("INCRBY", "fixed window accepted across boundary", accepted[0], Exactly(10))
+	expect("ZADD", "sliding log accepted across boundary", accepted[1], Exactly(5))
+
+	// Retry-After - Each limiter says when the next request could pass
+	fmt.Println("\n3. Retry-after reported on rejection:")
+	for _, l := range limiters {
+		res, err := l.Allow(ctx, "playground-edge", 1)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("   %-13s allowed %-5v remaining %d, retry after %v\n", l.name, res.Allowed, res.Remaining, res.RetryAfter)
+	}
+
+	// net/http middleware - 429 with Retry-After once the limit is hit
+	fmt.Printf("\n4. HTTP middleware (GCRA, 3 per %v, burst 3):\n", unit)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "hello")
+	})
+	handler := ratelimit.Middleware(ratelimit.NewGCRA(rdb, 3, unit, 3), func(r *http.Request) string {
+		return "playground-http:" + ratelimit.ClientIP(r)
+	})(ok)
+
+	var statuses []int
+	for i := 1; i <= 5; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		statuses = append(statuses, rec.Code)
+		fmt.Printf("   GET / #%d -> %d, X-RateLimit-Remaining %s, Retry-After %q\n",
+			i, rec.Code, rec.Header().Get("X-RateLimit-Remaining"), rec.Header().Get("Retry-After"))
+	}
+	expect("EVALSHA", "middleware status codes", statuses, Exactly([]int{200, 200, 200, 429, 429}))
+
+	// Cleanup
+	fmt.Println("\n5. Cleanup:")
+	cleanupRateLimitKeys(ctx, rdb)
+	fmt.Println("   Cleaned up rate limit examples ✓")
+}
+
+// cleanupRateLimitKeys deletes the keys the rate limit examples create
+func cleanupRateLimitKeys(ctx context.Context, rdb *redis.Client) {
+	iter := rdb.Scan(ctx, 0, "ratelimit:*playground-*", 100).Iterator()
+	for iter.Next(ctx) {
+		rdb.Del(ctx, iter.Val())
+	}
+}
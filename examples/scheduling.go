@@ -0,0 +1,134 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"redis-playground/scheduler"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunSchedulerExamples demonstrates the scheduler package with several
+// workers sharing delayed, cancelled, rescheduled and recurring jobs
+func RunSchedulerExamples(rdb *redis.Client) {
+	fmt.Println("\n Delayed Job Scheduler")
+	fmt.Println("========================")
+
+	ctx := context.Background()
+	sched := scheduler.New(rdb, "demo", scheduler.Options{PollInterval: 20 * time.Millisecond, Batch: 2})
+	sched.Delete(ctx)
+
+	// 1. Jobs go into a sorted set scored by due time
+	fmt.Println("1. Scheduling jobs:")
+	start := time.Now().Truncate(time.Millisecond) // scores are whole milliseconds
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	for _, s := range []struct {
+		job scheduler.Job
+		in  time.Duration
+	}{
+		{scheduler.Job{ID: "send-welcome-email", Payload: "user 42"}, 300 * time.Millisecond},
+		{scheduler.Job{ID: "build-report", Payload: "daily"}, 100 * time.Millisecond},
+		{scheduler.Job{ID: "send-reminder", Payload: "user 7"}, 600 * time.Millisecond},
+		{scheduler.Job{ID: "expire-trial", Payload: "user 9"}, 400 * time.Millisecond},
+		{scheduler.Job{ID: "rotate-logs", Payload: "nightly"}, 5 * time.Second},
+		{scheduler.Job{ID: "refresh-cache", Payload: "hot keys", Every: 250 * time.Millisecond}, 50 * time.Millisecond},
+	} {
+		if _, err := sched.Schedule(ctx, s.job, at(s.in)); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	printSchedule(ctx, sched, start)
+
+	// 2. Jobs are addressed by ID until they run
+	fmt.Println("\n2. Cancelling and rescheduling by ID:")
+	if err := sched.Cancel(ctx, "expire-trial"); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("   Cancelled expire-trial")
+	if err := sched.Reschedule(ctx, "rotate-logs", at(200*time.Millisecond)); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("   Moved rotate-logs from +5s to +200ms")
+	err := sched.Cancel(ctx, "no-such-job")
+	fmt.Printf("   Cancelling an unknown job: %v\n", err)
+	expect("HDEL", "cancel unknown job", err, Exactly(scheduler.ErrNotFound))
+	printSchedule(ctx, sched, start)
+
+	// 3. Several workers claim from the same set, each run goes to exactly one
+	fmt.Println("\n3. Four workers running jobs for 1.2s:")
+	type run struct {
+		id  string
+		due time.Time
+	}
+	var mu sync.Mutex
+	runs := make(map[run]int)
+	perJob := make(map[string]int)
+
+	runCtx, cancel := context.WithTimeout(ctx, 1200*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	for w := 1; w <= 4; w++ {
+		worker := fmt.Sprintf("worker-%d", w)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sched.Run(runCtx, func(ctx context.Context, job scheduler.Job) error {
+				late := time.Since(job.Due).Round(time.Millisecond)
+				fmt.Printf("   [+%4dms] %s ran %s (%s), %v after its due time\n",
+					time.Since(start).Milliseconds(), worker, job.ID, job.Payload, late)
+				mu.Lock()
+				runs[run{job.ID, job.Due}]++
+				perJob[job.ID]++
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond) // the actual work
+				return nil
+			})
+			if err != nil {
+				fmt.Printf("   %s: %v\n", worker, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 4. Results
+	fmt.Println("\n4. Results:")
+	duplicates := 0
+	for _, n := range runs {
+		if n > 1 {
+			duplicates++
+		}
+	}
+	stats := sched.Stats()
+	fmt.Printf("   claimed %d, completed %d, cancelled %d, rescheduled %d\n",
+		stats.Claimed, stats.Completed, stats.Cancelled, stats.Rescheduled)
+	fmt.Printf("   refresh-cache ran %d times, runs handled twice: %d\n", perJob["refresh-cache"], duplicates)
+	expect("EVALSHA", "runs handled by more than one worker", duplicates, Exactly(0))
+	expect("EVALSHA", "one-off jobs run", []int{perJob["build-report"], perJob["rotate-logs"], perJob["send-welcome-email"], perJob["send-reminder"]},
+		Exactly([]int{1, 1, 1, 1}))
+	expect("ZREM", "cancelled job runs", perJob["expire-trial"], Exactly(0))
+	expect("ZADD", "recurring job runs", float64(perJob["refresh-cache"]), InRange(4, 5))
+	printSchedule(ctx, sched, start)
+
+	// Cleanup
+	sched.Delete(ctx)
+	fmt.Println("\n5. Cleanup: Cleaned up scheduler examples ✓")
+}
+
+// printSchedule lists the waiting jobs with their due times relative to start
+func printSchedule(ctx context.Context, sched *scheduler.Scheduler, start time.Time) {
+	ids, due, err := sched.Pending(ctx)
+	if err != nil {
+		fmt.Printf("   Error: %v\n", err)
+		return
+	}
+	fmt.Print("   Waiting:")
+	for i, id := range ids {
+		fmt.Printf(" %s@+%dms", id, due[i].Sub(start).Milliseconds())
+	}
+	fmt.Println()
+}
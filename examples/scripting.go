@@ -0,0 +1,163 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"redis-playground/scripting"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// setIfGreater sets KEYS[1] to ARGV[1] unless it already holds a larger number
+const setIfGreater = `
+local current = tonumber(redis.call('GET', KEYS[1]))
+local candidate = tonumber(ARGV[1])
+if current == nil or candidate > current then
+  redis.call('SET', KEYS[1], ARGV[1])
+  return candidate
+end
+return current
+`
+
+// RunScriptingExamples demonstrates Lua scripting and the script registry
+func RunScriptingExamples(rdb *redis.Client) {
+	fmt.Println("\n Lua Scripting")
+	fmt.Println("================")
+
+	ctx := context.Background()
+	highScore := "lua:highscore"
+	owner := "lua:owner"
+
+	// EVAL - Run a script with KEYS and ARGV
+	fmt.Println("1. Running scripts with EVAL, KEYS and ARGV:")
+	echo, err := rdb.Eval(ctx, "return {KEYS[1], ARGV[1], #KEYS, #ARGV}", []string{"some:key"}, "some-arg").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   EVAL \"return {KEYS[1], ARGV[1], #KEYS, #ARGV}\" 1 some:key some-arg -> %v\n", echo)
+
+	// Declaring every key in KEYS lets Redis route the script in a cluster
+	var best int64
+	for _, score := range []int{40, 95, 70} {
+		best, err = rdb.Eval(ctx, setIfGreater, []string{highScore}, score).Int64()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("   set-if-greater %d -> high score %d\n", score, best)
+	}
+	expect("EVAL", "high score after set-if-greater", best, Exactly(int64(95)))
+
+	// SCRIPT LOAD / EVALSHA - Send the source once, then call it by digest
+	fmt.Println("\n2. Caching scripts with SCRIPT LOAD and EVALSHA:")
+	sha, err := rdb.ScriptLoad(ctx, setIfGreater).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   SCRIPT LOAD -> %s\n", sha)
+
+	best, err = rdb.EvalSha(ctx, sha, []string{highScore}, 120).Int64()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   EVALSHA %s... 1 %s 120 -> %d\n", sha[:8], highScore, best)
+	expect("EVALSHA", "high score after EVALSHA", best, Exactly(int64(120)))
+
+	cached, _ := rdb.ScriptExists(ctx, sha).Result()
+	fmt.Printf("   SCRIPT EXISTS -> %v\n", cached)
+
+	// NOSCRIPT - The script cache is not persistent, a restart, failover or
+	// SCRIPT FLUSH empties it
+	fmt.Println("\n3. Recovering from NOSCRIPT:")
+	rdb.ScriptFlush(ctx)
+	err = rdb.EvalSha(ctx, sha, []string{highScore}, 130).Err()
+	fmt.Printf("   After SCRIPT FLUSH, EVALSHA fails: %v\n", err)
+	expect("EVALSHA", "NOSCRIPT after flush", err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT"), Exactly(true))
+
+	// go-redis Script does EVALSHA and falls back to EVAL on NOSCRIPT
+	script := redis.NewScript(setIfGreater)
+	best, err = script.Run(ctx, rdb, []string{highScore}, 130).Int64()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   redis.Script.Run falls back to EVAL -> %d\n", best)
+
+	// The registry - Versioned .lua files called by name
+	fmt.Println("\n4. Calling versioned scripts from the registry:")
+	registry, err := scripting.LoadFS(scripting.Builtin, "lua")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := registry.Preload(ctx, rdb); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, s := range registry.Scripts() {
+		fmt.Printf("   %-4s v%d  %s  (%s)\n", s.Name, s.Version, s.SHA[:12], s.File)
+	}
+	cas, _ := registry.Get("cas")
+	expect("SCRIPT LOAD", "latest cas version", cas.Version, Exactly(2))
+
+	// Compare-and-set only swaps when we saw the current value
+	rdb.Set(ctx, owner, "worker-a", 0)
+	swapped, err := registry.Bool(ctx, rdb, "cas", []string{owner}, "worker-b", "worker-c")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   cas %s worker-b -> worker-c: %v (it holds worker-a)\n", owner, swapped)
+	expect("EVALSHA", "cas with stale value", swapped, Exactly(false))
+
+	swapped, _ = registry.Bool(ctx, rdb, "cas", []string{owner}, "worker-a", "worker-b", 60000)
+	ttl, _ := rdb.PTTL(ctx, owner).Result()
+	fmt.Printf("   cas %s worker-a -> worker-b PX 60000: %v, TTL %v\n", owner, swapped, ttl.Round(time.Second))
+	expect("EVALSHA", "cas with current value", swapped, Exactly(true))
+
+	// Compare-and-delete only removes the key while we still own it
+	deleted, _ := registry.Int64(ctx, rdb, "cad", []string{owner}, "worker-a")
+	fmt.Printf("   cad %s worker-a: deleted %d\n", owner, deleted)
+	deleted, _ = registry.Int64(ctx, rdb, "cad", []string{owner}, "worker-b")
+	fmt.Printf("   cad %s worker-b: deleted %d\n", owner, deleted)
+	expect("EVALSHA", "cad by the owner", deleted, Exactly(int64(1)))
+
+	// The registry reloads flushed scripts itself
+	rdb.ScriptFlush(ctx)
+	rdb.Set(ctx, owner, "worker-a", 0)
+	deleted, err = registry.Int64(ctx, rdb, "cad", []string{owner}, "worker-a")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   After SCRIPT FLUSH, cad still works: deleted %d, reloads %d\n", deleted, registry.Reloads())
+	expect("EVALSHA", "registry reloads after NOSCRIPT", registry.Reloads(), Exactly(int64(1)))
+
+	// Script errors - The registry reports the script, or its file, and the line
+	fmt.Println("\n5. Script errors with line numbers:")
+	registry.Register("broken", 1, "", "local total = 0\nfor i = 1, 3 do\n  total = total + undefined_var\nend\nreturn total\n")
+	_, err = registry.Int64(ctx, rdb, "broken", nil)
+	fmt.Printf("   %v\n", err)
+
+	var scriptErr *scripting.ScriptError
+	line := 0
+	if errors.As(err, &scriptErr) {
+		line = scriptErr.Line
+	}
+	expect("EVALSHA", "runtime error line", line, Exactly(3))
+
+	registry.Register("typo", 1, "", "local x = 1\nif x == 1\n  return x\nend\n")
+	err = registry.Preload(ctx, rdb)
+	fmt.Printf("   %v\n", err)
+
+	// Cleanup
+	fmt.Println("\n6. Cleanup:")
+	rdb.Del(ctx, highScore, owner)
+	fmt.Println("   Cleaned up scripting examples ✓")
+}
@@ -3,6 +3,7 @@ package examples
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -25,6 +26,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Added %d interests to user:123\n", added)
+	expect("SADD", "interests added", added, Exactly(int64(4)))
 
 	// Try adding duplicate (won't be added)
 	added, err = rdb.SAdd(ctx, interestSet, "programming", "reading").Result()
@@ -33,6 +35,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Added %d new interests (duplicates ignored)\n", added)
+	expect("SADD", "new interests added", added, Exactly(int64(1)))
 
 	// SMEMBERS - Get all members
 	fmt.Println("\n2. Getting all members with SMEMBERS:")
@@ -42,6 +45,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   User interests: %v\n", interests)
+	expect("SMEMBERS", "user interests", interests, SameMembers("programming", "music", "travel", "photography", "reading"))
 
 	// SCARD - Get set size
 	fmt.Println("\n3. Getting set size with SCARD:")
@@ -51,6 +55,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Number of interests: %d\n", size)
+	expect("SCARD", "number of interests", size, Exactly(int64(5)))
 
 	// SISMEMBER - Check if member exists
 	fmt.Println("\n4. Checking membership with SISMEMBER:")
@@ -60,6 +65,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Is 'programming' an interest? %t\n", isMember)
+	expect("SISMEMBER", "programming is an interest", isMember, Exactly(true))
 
 	isMember, err = rdb.SIsMember(ctx, interestSet, "cooking").Result()
 	if err != nil {
@@ -67,6 +73,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Is 'cooking' an interest? %t\n", isMember)
+	expect("SISMEMBER", "cooking is an interest", isMember, Exactly(false))
 
 	// Create another user's interests for set operations
 	fmt.Println("\n5. Creating another user's interests:")
@@ -79,6 +86,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   User 456 interests: %v\n", otherInterests)
+	expect("SMEMBERS", "user 456 interests", otherInterests, SameMembers("programming", "gaming", "travel", "cooking"))
 
 	// SINTER - Set intersection (common interests)
 	fmt.Println("\n6. Finding common interests with SINTER:")
@@ -88,6 +96,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Common interests: %v\n", commonInterests)
+	expect("SINTER", "common interests", commonInterests, SameMembers("programming", "travel"))
 
 	// SUNION - Set union (all unique interests)
 	fmt.Println("\n7. Finding all unique interests with SUNION:")
@@ -97,6 +106,8 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   All unique interests: %v\n", allInterests)
+	expect("SUNION", "all unique interests", allInterests,
+		SameMembers("programming", "music", "travel", "photography", "reading", "gaming", "cooking"))
 
 	// SDIFF - Set difference (interests only in first set)
 	fmt.Println("\n8. Finding unique interests with SDIFF:")
@@ -106,6 +117,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Interests unique to user 123: %v\n", uniqueToUser123)
+	expect("SDIFF", "interests unique to user 123", uniqueToUser123, SameMembers("music", "photography", "reading"))
 
 	uniqueToUser456, err := rdb.SDiff(ctx, otherInterestSet, interestSet).Result()
 	if err != nil {
@@ -113,6 +125,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Interests unique to user 456: %v\n", uniqueToUser456)
+	expect("SDIFF", "interests unique to user 456", uniqueToUser456, SameMembers("gaming", "cooking"))
 
 	// SPOP - Remove and return random member
 	fmt.Println("\n9. Random operations with SPOP and SRANDMEMBER:")
@@ -122,6 +135,15 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Randomly removed interest: %s\n", randomInterest)
+	expect("SPOP", "randomly removed interest", randomInterest, OneOf(interests...))
+
+	// Everything SPOP left behind
+	var afterPop []string
+	for _, interest := range interests {
+		if interest != randomInterest {
+			afterPop = append(afterPop, interest)
+		}
+	}
 
 	// SRANDMEMBER - Get random member without removing
 	randomMember, err := rdb.SRandMember(ctx, interestSet).Result()
@@ -130,6 +152,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Random interest (not removed): %s\n", randomMember)
+	expect("SRANDMEMBER", "random interest", randomMember, OneOf(afterPop...))
 
 	// Get multiple random members
 	randomMembers, err := rdb.SRandMemberN(ctx, interestSet, 2).Result()
@@ -138,6 +161,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   2 random interests: %v\n", randomMembers)
+	expect("SRANDMEMBER", "2 random interests", randomMembers, SampleOf(2, afterPop...))
 
 	// SREM - Remove specific members
 	fmt.Println("\n10. Removing specific members with SREM:")
@@ -147,6 +171,13 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Removed %d member(s)\n", removed)
+	var afterRemove []string
+	for _, interest := range afterPop {
+		if interest != "music" {
+			afterRemove = append(afterRemove, interest)
+		}
+	}
+	expect("SREM", "members removed", removed, Exactly(int64(len(afterPop)-len(afterRemove))))
 
 	remainingInterests, err := rdb.SMembers(ctx, interestSet).Result()
 	if err != nil {
@@ -154,36 +185,71 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Remaining interests: %v\n", remainingInterests)
+	expect("SMEMBERS", "remaining interests", remainingInterests, SameMembers(afterRemove...))
 
 	// Practical example: Tagging system
 	fmt.Println("\n11. Practical example - Article tagging system:")
 
-	// Article tags
-	rdb.SAdd(ctx, "article:1:tags", "redis", "database", "nosql", "performance")
-	rdb.SAdd(ctx, "article:2:tags", "golang", "programming", "performance", "backend")
-	rdb.SAdd(ctx, "article:3:tags", "redis", "golang", "tutorial", "backend")
+	// Article tags (three articles unless a dataset is loaded)
+	start := time.Now()
+	var articles []string
+	var wantRedis, wantBoth []string
+	for _, article := range articleData {
+		key := "article:" + article.ID + ":tags"
+		rdb.SAdd(ctx, key, article.Tags)
+		articles = append(articles, key)
+
+		tags := make(map[string]bool)
+		for _, tag := range article.Tags {
+			tags[tag] = true
+		}
+		if tags["redis"] {
+			wantRedis = append(wantRedis, key)
+		}
+		if tags["performance"] && tags["backend"] {
+			wantBoth = append(wantBoth, key)
+		}
+	}
+	if len(articleData) > 3 {
+		fmt.Printf("   Tagged %d articles in %v\n", len(articleData), time.Since(start))
+	}
 
 	// Find articles with common tags
 	fmt.Println("   Articles tagged with 'redis':")
 	// In a real system, you'd maintain reverse indexes
 	// For demo, we'll check each article
-	articles := []string{"article:1:tags", "article:2:tags", "article:3:tags"}
+	var taggedRedis []string
 	for i, article := range articles {
 		hasRedis, _ := rdb.SIsMember(ctx, article, "redis").Result()
 		if hasRedis {
-			fmt.Printf("     Article %d has 'redis' tag\n", i+1)
+			if len(taggedRedis) < maxListed {
+				fmt.Printf("     Article %s has 'redis' tag\n", articleData[i].ID)
+			}
+			taggedRedis = append(taggedRedis, article)
 		}
 	}
+	if len(taggedRedis) > maxListed {
+		fmt.Printf("     ... and %d more\n", len(taggedRedis)-maxListed)
+	}
+	expect("SISMEMBER", "articles tagged 'redis'", taggedRedis, SameMembers(wantRedis...))
 
 	// Find articles with multiple tags (intersection example)
 	fmt.Println("   Articles tagged with both 'performance' AND 'backend':")
+	var taggedBoth []string
 	for i, article := range articles {
 		hasPerf, _ := rdb.SIsMember(ctx, article, "performance").Result()
 		hasBackend, _ := rdb.SIsMember(ctx, article, "backend").Result()
 		if hasPerf && hasBackend {
-			fmt.Printf("     Article %d has both tags\n", i+1)
+			if len(taggedBoth) < maxListed {
+				fmt.Printf("     Article %s has both tags\n", articleData[i].ID)
+			}
+			taggedBoth = append(taggedBoth, article)
 		}
 	}
+	if len(taggedBoth) > maxListed {
+		fmt.Printf("     ... and %d more\n", len(taggedBoth)-maxListed)
+	}
+	expect("SISMEMBER", "articles tagged 'performance' and 'backend'", taggedBoth, SameMembers(wantBoth...))
 
 	// Practical example: Online users tracking
 	fmt.Println("\n12. Practical example - Online users tracking:")
@@ -199,6 +265,7 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Online users: %v\n", online)
+	expect("SMEMBERS", "online users", online, SameMembers("user:123", "user:456", "user:789"))
 
 	// User goes offline
 	rdb.SRem(ctx, onlineUsers, "user:456")
@@ -210,9 +277,10 @@ func RunSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Online user count: %d\n", onlineCount)
+	expect("SCARD", "online user count", onlineCount, Exactly(int64(2)))
 
 	// Cleanup
 	fmt.Println("\n13. Cleanup:")
-	rdb.Del(ctx, interestSet, otherInterestSet, "article:1:tags", "article:2:tags", "article:3:tags", onlineUsers)
+	rdb.Del(ctx, append([]string{interestSet, otherInterestSet, onlineUsers}, articles...)...)
 	fmt.Println("   Cleaned up set examples ✓")
 }
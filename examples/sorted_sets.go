@@ -3,6 +3,9 @@ package examples
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -19,21 +22,25 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 
 	leaderboard := "game:leaderboard"
 
-	// Add players with their scores
-	players := []redis.Z{
-		{Score: 1500, Member: "alice"},
-		{Score: 2300, Member: "bob"},
-		{Score: 1800, Member: "charlie"},
-		{Score: 2100, Member: "diana"},
-		{Score: 1200, Member: "eve"},
-	}
+	// Add players with their scores (alice to eve unless a dataset is loaded)
+	players := leaderboardData
+	model := newZSetModel(players)
+
+	// The walkthrough follows the first player and later removes the last one
+	featured := fmt.Sprint(players[0].Member)
+	dropped := fmt.Sprint(players[len(players)-1].Member)
 
+	start := time.Now()
 	added, err := rdb.ZAdd(ctx, leaderboard, players...).Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	fmt.Printf("   Added %d players to leaderboard\n", added)
+	if len(players) > maxListed {
+		fmt.Printf("   (ZADD of %d players took %v)\n", len(players), time.Since(start))
+	}
+	expect("ZADD", "players added", added, Exactly(int64(len(model))))
 
 	// ZRANGE - Get members by rank (ascending order)
 	fmt.Println("\n2. Getting members by rank with ZRANGE:")
@@ -44,10 +51,9 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("ZRANGE", "all players ascending", allPlayers, Exactly(model.ascending()))
 	fmt.Println("   All players (ascending):")
-	for i, player := range allPlayers {
-		fmt.Printf("     %d. %s: %.0f points\n", i+1, player.Member, player.Score)
-	}
+	printPlayers(allPlayers)
 
 	// ZREVRANGE - Get members by rank (descending order)
 	fmt.Println("\n3. Getting top players with ZREVRANGE:")
@@ -58,6 +64,7 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("ZREVRANGE", "top 3 players", topPlayers, Exactly(model.top(3)))
 	fmt.Println("   Top 3 players:")
 	for i, player := range topPlayers {
 		fmt.Printf("     %d. %s: %.0f points\n", i+1, player.Member, player.Score)
@@ -65,29 +72,32 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 
 	// ZSCORE - Get score of specific member
 	fmt.Println("\n4. Getting specific scores with ZSCORE:")
-	aliceScore, err := rdb.ZScore(ctx, leaderboard, "alice").Result()
+	score, err := rdb.ZScore(ctx, leaderboard, featured).Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	fmt.Printf("   Alice's score: %.0f\n", aliceScore)
+	fmt.Printf("   %s's score: %.0f\n", capitalize(featured), score)
+	expect("ZSCORE", "featured player's score", score, Exactly(model[featured]))
 
 	// ZRANK - Get rank of member (0-based, ascending)
 	fmt.Println("\n5. Getting player ranks with ZRANK and ZREVRANK:")
-	aliceRank, err := rdb.ZRank(ctx, leaderboard, "alice").Result()
+	rank, err := rdb.ZRank(ctx, leaderboard, featured).Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	fmt.Printf("   Alice's rank (ascending): %d\n", aliceRank)
+	fmt.Printf("   %s's rank (ascending): %d\n", capitalize(featured), rank)
+	expect("ZRANK", "featured player's ascending rank", rank, Exactly(model.rank(featured)))
 
 	// ZREVRANK - Get rank of member (0-based, descending)
-	aliceRevRank, err := rdb.ZRevRank(ctx, leaderboard, "alice").Result()
+	revRank, err := rdb.ZRevRank(ctx, leaderboard, featured).Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	fmt.Printf("   Alice's rank (descending): %d (position from top)\n", aliceRevRank)
+	fmt.Printf("   %s's rank (descending): %d (position from top)\n", capitalize(featured), revRank)
+	expect("ZREVRANK", "featured player's descending rank", revRank, Exactly(int64(len(model))-1-model.rank(featured)))
 
 	// ZCARD - Get number of members
 	fmt.Println("\n6. Getting leaderboard size with ZCARD:")
@@ -97,15 +107,18 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Total players: %d\n", playerCount)
+	expect("ZCARD", "total players", playerCount, Exactly(int64(len(model))))
 
 	// ZINCRBY - Increment member score
 	fmt.Println("\n7. Updating scores with ZINCRBY:")
-	newScore, err := rdb.ZIncrBy(ctx, leaderboard, 300, "alice").Result()
+	newScore, err := rdb.ZIncrBy(ctx, leaderboard, 300, featured).Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	fmt.Printf("   Alice's new score after +300: %.0f\n", newScore)
+	fmt.Printf("   %s's new score after +300: %.0f\n", capitalize(featured), newScore)
+	model[featured] += 300
+	expect("ZINCRBY", "featured player's new score", newScore, Exactly(model[featured]))
 
 	// Check new rankings
 	newTopPlayers, err := rdb.ZRevRangeWithScores(ctx, leaderboard, 0, 2).Result()
@@ -113,6 +126,8 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	// Ties are ordered by member in reverse, so charlie stays ahead of alice
+	expect("ZREVRANGE", "updated top 3", newTopPlayers, Exactly(model.top(3)))
 	fmt.Println("   Updated top 3:")
 	for i, player := range newTopPlayers {
 		fmt.Printf("     %d. %s: %.0f points\n", i+1, player.Member, player.Score)
@@ -130,8 +145,13 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("ZRANGEBYSCORE", "players with scores 1500-2000", midRangePlayers, Exactly(model.byScore(1500, 2000)))
 	fmt.Println("   Players with scores 1500-2000:")
-	for _, player := range midRangePlayers {
+	for i, player := range midRangePlayers {
+		if i == maxListed {
+			fmt.Printf("     ... and %d more\n", len(midRangePlayers)-maxListed)
+			break
+		}
 		fmt.Printf("     %s: %.0f points\n", player.Member, player.Score)
 	}
 
@@ -143,15 +163,18 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Players with scores 1500-2000: %d\n", count)
+	expect("ZCOUNT", "players with scores 1500-2000", count, Exactly(int64(len(model.byScore(1500, 2000)))))
 
 	// ZREM - Remove members
 	fmt.Println("\n10. Removing players with ZREM:")
-	removed, err := rdb.ZRem(ctx, leaderboard, "eve").Result()
+	removed, err := rdb.ZRem(ctx, leaderboard, dropped).Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	fmt.Printf("   Removed %d player(s)\n", removed)
+	expect("ZREM", "players removed", removed, Exactly(int64(1)))
+	delete(model, dropped)
 
 	// ZREMRANGEBYRANK - Remove by rank range
 	fmt.Println("\n11. Removing bottom players with ZREMRANGEBYRANK:")
@@ -161,6 +184,10 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		return
 	}
 	fmt.Printf("   Removed %d bottom player(s)\n", removedByRank)
+	expect("ZREMRANGEBYRANK", "bottom players removed", removedByRank, Exactly(int64(min(1, len(model)))))
+	if bottom := model.ascending(); len(bottom) > 0 {
+		delete(model, bottom[0].Member.(string))
+	}
 
 	// Final leaderboard
 	finalLeaderboard, err := rdb.ZRevRangeWithScores(ctx, leaderboard, 0, -1).Result()
@@ -168,25 +195,23 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("ZREVRANGE", "final leaderboard", finalLeaderboard, Exactly(model.descending()))
 	fmt.Println("   Final leaderboard:")
-	for i, player := range finalLeaderboard {
-		fmt.Printf("     %d. %s: %.0f points\n", i+1, player.Member, player.Score)
-	}
+	printPlayers(finalLeaderboard)
 
 	// Practical example: Time-series data (using timestamps as scores)
 	fmt.Println("\n12. Practical example - Time-series data:")
 	timeSeriesKey := "sensor:temperature"
 
 	// Add temperature readings with timestamps as scores
-	readings := []redis.Z{
-		{Score: 1640995200, Member: "22.5"},
-		{Score: 1640995260, Member: "23.1"},
-		{Score: 1640995320, Member: "22.8"},
-		{Score: 1640995380, Member: "23.4"},
-		{Score: 1640995440, Member: "23.0"},
-	}
+	readings := readingData
+	readingModel := newZSetModel(readings)
 
+	start = time.Now()
 	rdb.ZAdd(ctx, timeSeriesKey, readings...)
+	if len(readings) > maxListed {
+		fmt.Printf("   (ZADD of %d readings took %v)\n", len(readings), time.Since(start))
+	}
 
 	// Get latest 3 readings
 	latest, err := rdb.ZRevRangeWithScores(ctx, timeSeriesKey, 0, 2).Result()
@@ -194,23 +219,30 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("ZREVRANGE", "latest 3 readings", latest, Exactly(readingModel.top(3)))
 	fmt.Println("   Latest 3 temperature readings:")
 	for _, reading := range latest {
-		fmt.Printf("     Timestamp %.0f: %s°C\n", reading.Score, reading.Member)
+		fmt.Printf("     Timestamp %.0f: %s°C\n", reading.Score, readingValue(reading.Member))
 	}
 
 	// Get readings in time range
+	first := readingModel.ascending()[0].Score
 	timeRange, err := rdb.ZRangeByScoreWithScores(ctx, timeSeriesKey, &redis.ZRangeBy{
-		Min: "1640995200",
-		Max: "1640995320",
+		Min: strconv.FormatFloat(first, 'f', -1, 64),
+		Max: strconv.FormatFloat(first+120, 'f', -1, 64),
 	}).Result()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
+	expect("ZRANGEBYSCORE", "readings in first 2 minutes", timeRange, Exactly(readingModel.byScore(first, first+120)))
 	fmt.Println("   Readings in first 2 minutes:")
-	for _, reading := range timeRange {
-		fmt.Printf("     Timestamp %.0f: %s°C\n", reading.Score, reading.Member)
+	for i, reading := range timeRange {
+		if i == maxListed {
+			fmt.Printf("     ... and %d more\n", len(timeRange)-maxListed)
+			break
+		}
+		fmt.Printf("     Timestamp %.0f: %s°C\n", reading.Score, readingValue(reading.Member))
 	}
 
 	// Practical example: Priority queue
@@ -230,22 +262,42 @@ func RunSortedSetsExamples(rdb *redis.Client) {
 
 	// Process tasks by priority (highest first)
 	fmt.Println("   Processing tasks by priority:")
+	var processed []string
 	for i := 0; i < 3; i++ {
-		// Get highest priority task
-		highestPriority, err := rdb.ZRevRangeWithScores(ctx, priorityQueue, 0, 0).Result()
+		// Take the highest priority task in one step, so two workers can
+		// never both read the same top entry before either removes it
+		highestPriority, err := rdb.ZPopMax(ctx, priorityQueue).Result()
 		if err != nil || len(highestPriority) == 0 {
 			break
 		}
 
 		task := highestPriority[0]
 		fmt.Printf("     Processing (priority %.0f): %s\n", task.Score, task.Member)
-
-		// Remove processed task
-		rdb.ZRem(ctx, priorityQueue, task.Member)
+		processed = append(processed, fmt.Sprint(task.Member))
 	}
+	expect("ZPOPMAX", "processing order", processed, Exactly([]string{"fix_critical_bug", "security_patch", "deploy_feature"}))
 
 	// Cleanup
 	fmt.Println("\n14. Cleanup:")
 	rdb.Del(ctx, leaderboard, timeSeriesKey, priorityQueue)
 	fmt.Println("   Cleaned up sorted set examples ✓")
 }
+
+// printPlayers prints a ranked leaderboard, truncated to maxListed entries
+func printPlayers(players []redis.Z) {
+	for i, player := range players {
+		if i == maxListed {
+			fmt.Printf("     ... and %d more\n", len(players)-maxListed)
+			return
+		}
+		fmt.Printf("     %d. %s: %.0f points\n", i+1, player.Member, player.Score)
+	}
+}
+
+// capitalize upper-cases the first letter of a player name for display
+func capitalize(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,287 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunStreamsExamples demonstrates Redis stream operations and consumer groups
+func RunStreamsExamples(rdb *redis.Client) {
+	fmt.Println("\n Stream Operations")
+	fmt.Println("====================")
+
+	ctx := context.Background()
+	stream := "events:orders"
+	explicit := "events:explicit"
+	jobs := "events:jobs"
+	group := "order-processors"
+	// A run that stopped early leaves entries with higher IDs and the group
+	// behind, which would make the XADD and XGROUP CREATE below fail
+	rdb.Del(ctx, stream, explicit, jobs)
+
+	// XADD - Append entries with auto-generated IDs
+	fmt.Println("1. Appending entries with XADD (auto IDs):")
+	for i, item := range []string{"keyboard", "monitor", "mouse"} {
+		id, err := rdb.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			ID:     "*",
+			Values: map[string]interface{}{"order": i + 1, "item": item},
+		}).Result()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("   XADD %s * order %d item %s -> %s\n", stream, i+1, item, id)
+	}
+
+	length, err := rdb.XLen(ctx, stream).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Stream length: %d\n", length)
+	expect("XLEN", "stream length after XADD", length, Exactly(int64(3)))
+
+	// XADD with explicit IDs - IDs must always increase
+	fmt.Println("\n2. Appending entries with explicit IDs:")
+	for _, id := range []string{"1-1", "1-2", "2-0"} {
+		_, err := rdb.XAdd(ctx, &redis.XAddArgs{
+			Stream: explicit,
+			ID:     id,
+			Values: []string{"reading", id},
+		}).Result()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Printf("   XADD %s %s ✓\n", explicit, id)
+	}
+
+	_, err = rdb.XAdd(ctx, &redis.XAddArgs{Stream: explicit, ID: "1-5", Values: []string{"reading", "late"}}).Result()
+	fmt.Printf("   XADD %s 1-5 rejected: %v\n", explicit, err)
+	expect("XADD", "smaller explicit ID rejected", err != nil, Exactly(true))
+
+	// XRANGE / XREVRANGE - Read entries by ID range
+	fmt.Println("\n3. Reading ranges with XRANGE and XREVRANGE:")
+	entries, err := rdb.XRange(ctx, explicit, "-", "+").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("   XRANGE - + (oldest first):")
+	for _, e := range entries {
+		fmt.Printf("     %s %v\n", e.ID, e.Values)
+	}
+	expect("XRANGE", "explicit IDs in order", messageIDs(entries), Exactly([]string{"1-1", "1-2", "2-0"}))
+
+	newest, err := rdb.XRevRangeN(ctx, stream, "+", "-", 2).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Println("   XREVRANGE + - COUNT 2 (newest first):")
+	for _, e := range newest {
+		fmt.Printf("     %s item=%v\n", e.ID, e.Values["item"])
+	}
+	if len(newest) < 2 {
+		fmt.Printf("Error: expected 2 entries from XREVRANGE, got %d\n", len(newest))
+		return
+	}
+	expect("XREVRANGE", "two newest items", []interface{}{newest[0].Values["item"], newest[1].Values["item"]},
+		Exactly([]interface{}{"mouse", "monitor"}))
+
+	// XREAD BLOCK - Wait for new entries
+	fmt.Println("\n4. Waiting for new entries with blocking XREAD:")
+
+	// Reading from the last ID we know about, rather than "$", means an entry
+	// added before the XREAD reaches the server is not missed
+	lastID := newest[0].ID
+	received := make(chan []redis.XStream, 1)
+	go func() {
+		res, err := rdb.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{stream, lastID},
+			Block:   2 * time.Second,
+		}).Result()
+		if err != nil {
+			fmt.Printf("   Reader error: %v\n", err)
+		}
+		received <- res
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	fmt.Println("   Reader is blocked, producer adds an entry...")
+	rdb.XAdd(ctx, &redis.XAddArgs{Stream: stream, Values: map[string]interface{}{"order": 4, "item": "webcam"}})
+
+	res := <-received
+	var woke []string
+	for _, s := range res {
+		for _, e := range s.Messages {
+			fmt.Printf("   Reader woke up with %s item=%v\n", e.ID, e.Values["item"])
+			woke = append(woke, fmt.Sprint(e.Values["item"]))
+		}
+	}
+	expect("XREAD", "blocked reader receives new entry", woke, Exactly([]string{"webcam"}))
+
+	// Consumer groups - XGROUP CREATE, XREADGROUP, XACK
+	fmt.Println("\n5. Consumer groups with XGROUP, XREADGROUP and XACK:")
+	err = rdb.XGroupCreateMkStream(ctx, jobs, group, "0").Err()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   XGROUP CREATE %s %s 0 MKSTREAM ✓\n", jobs, group)
+
+	const jobCount = 9
+	for i := 1; i <= jobCount; i++ {
+		rdb.XAdd(ctx, &redis.XAddArgs{Stream: jobs, Values: map[string]interface{}{"job": i}})
+	}
+	fmt.Printf("   Added %d jobs\n", jobCount)
+
+	// Three consumers read concurrently, worker-3 "crashes" after taking its
+	// first job without acknowledging it
+	var mu sync.Mutex
+	acked := make(map[string][]string)
+	var wg sync.WaitGroup
+	for _, consumer := range []string{"worker-1", "worker-2", "worker-3"} {
+		wg.Add(1)
+		go func(consumer string) {
+			defer wg.Done()
+			for {
+				res, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+					Group:    group,
+					Consumer: consumer,
+					Streams:  []string{jobs, ">"},
+					Count:    1,
+					Block:    300 * time.Millisecond,
+				}).Result()
+				if err == redis.Nil {
+					return // nothing new arrived before the block timeout
+				}
+				if err != nil {
+					fmt.Printf("   %s error: %v\n", consumer, err)
+					return
+				}
+
+				msg := res[0].Messages[0]
+				if consumer == "worker-3" {
+					fmt.Printf("   %s took job %v and crashed before XACK\n", consumer, msg.Values["job"])
+					return
+				}
+
+				time.Sleep(20 * time.Millisecond) // simulated work
+				rdb.XAck(ctx, jobs, group, msg.ID)
+
+				mu.Lock()
+				acked[consumer] = append(acked[consumer], fmt.Sprint(msg.Values["job"]))
+				mu.Unlock()
+			}
+		}(consumer)
+	}
+	wg.Wait()
+
+	total := 0
+	for _, consumer := range []string{"worker-1", "worker-2"} {
+		fmt.Printf("   %s processed and acknowledged jobs %v\n", consumer, acked[consumer])
+		total += len(acked[consumer])
+	}
+	expect("XREADGROUP", "jobs acknowledged by healthy workers", total, Exactly(jobCount-1))
+
+	// XPENDING - Inspect delivered but unacknowledged entries
+	fmt.Println("\n6. Inspecting unacknowledged entries with XPENDING:")
+	pending, err := rdb.XPending(ctx, jobs, group).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Pending entries: %d (per consumer: %v)\n", pending.Count, pending.Consumers)
+	expect("XPENDING", "pending entries", pending.Count, Exactly(int64(1)))
+
+	details, err := rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: jobs,
+		Group:  group,
+		Start:  "-",
+		End:    "+",
+		Count:  10,
+	}).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, p := range details {
+		fmt.Printf("   %s owned by %s, idle %v, delivered %d time(s)\n", p.ID, p.Consumer, p.Idle.Round(time.Millisecond), p.RetryCount)
+	}
+
+	// XAUTOCLAIM - Hand stalled entries to a healthy consumer
+	fmt.Println("\n7. Reclaiming stalled entries with XAUTOCLAIM:")
+	minIdle := 100 * time.Millisecond
+	time.Sleep(minIdle)
+	claimed, _, err := rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   jobs,
+		Group:    group,
+		Consumer: "worker-1",
+		MinIdle:  minIdle,
+		Start:    "0-0",
+		Count:    10,
+	}).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	for _, msg := range claimed {
+		fmt.Printf("   worker-1 claimed job %v (%s), processing and acknowledging\n", msg.Values["job"], msg.ID)
+		rdb.XAck(ctx, jobs, group, msg.ID)
+	}
+	expect("XAUTOCLAIM", "stalled entries claimed", len(claimed), Exactly(1))
+
+	pending, err = rdb.XPending(ctx, jobs, group).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   Pending entries after recovery: %d\n", pending.Count)
+	expect("XPENDING", "pending entries after recovery", pending.Count, Exactly(int64(0)))
+
+	// XTRIM - Cap stream size by length or by minimum ID
+	fmt.Println("\n8. Trimming with MAXLEN and MINID:")
+	trimmed, err := rdb.XTrimMaxLen(ctx, jobs, 5).Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	length, _ = rdb.XLen(ctx, jobs).Result()
+	fmt.Printf("   XTRIM %s MAXLEN 5: removed %d, length now %d\n", jobs, trimmed, length)
+	expect("XTRIM", "length after MAXLEN 5", length, Exactly(int64(5)))
+
+	trimmed, err = rdb.XTrimMinID(ctx, explicit, "1-2").Result()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	remaining, _ := rdb.XRange(ctx, explicit, "-", "+").Result()
+	fmt.Printf("   XTRIM %s MINID 1-2: removed %d, remaining IDs %v\n", explicit, trimmed, messageIDs(remaining))
+	expect("XTRIM", "IDs after MINID 1-2", messageIDs(remaining), Exactly([]string{"1-2", "2-0"}))
+
+	// Capping while adding keeps a stream bounded without separate XTRIM calls
+	rdb.XAdd(ctx, &redis.XAddArgs{Stream: jobs, MaxLen: 3, Values: map[string]interface{}{"job": jobCount + 1}})
+	length, _ = rdb.XLen(ctx, jobs).Result()
+	fmt.Printf("   XADD %s MAXLEN 3 * ...: length now %d\n", jobs, length)
+	expect("XADD", "length after XADD MAXLEN 3", length, Exactly(int64(3)))
+
+	// Cleanup
+	fmt.Println("\n9. Cleanup:")
+	rdb.Del(ctx, stream, explicit, jobs)
+	fmt.Println("   Cleaned up stream examples ✓")
+}
+
+// messageIDs returns the IDs of msgs in order
+func messageIDs(msgs []redis.XMessage) []string {
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	return ids
+}
@@ -25,19 +25,22 @@ func RunStringExamples(rdb *redis.Client) {
 		panic("Failed to get value: " + err.Error())
 	}
 	fmt.Printf("user:1 = %s\n", val)
+	expect("GET", "user:1 value", val, Exactly("Naim Islam"))
 
 	// SET with expiration
 	fmt.Println("\n2. SET with expiration (5 seconds):")
-	err = rdb.Set(ctx, "temp:session", "12345", 5*time.Second).Err()
+	err = rdb.Set(ctx, "temp:session", "12345", scaled(5*time.Second)).Err()
 	if err != nil {
 		panic("Failed to set value with expiration: " + err.Error())
 	}
 
-	ttl, err := rdb.TTL(ctx, "temp:session").Result()
+	ttlCmd := keyTTL(ctx, rdb, "temp:session")
+	ttl, err := ttlCmd.Result()
 	if err != nil {
 		panic("Failed to get TTL: " + err.Error())
 	}
 	fmt.Printf(" temp:session will expire in %s\n", ttl)
+	expect(commandName(ttlCmd), "temp:session TTL", ttl, Between(scaled(4*time.Second), scaled(5*time.Second)))
 
 	// INCR and DECR
 	fmt.Println("\n3. Increment and Decrement:")
@@ -51,12 +54,14 @@ func RunStringExamples(rdb *redis.Client) {
 		panic("Failed to increment counter: " + err.Error())
 	}
 	fmt.Printf("Counter after increment: %d\n", newVal)
+	expect("INCR", "counter after increment", newVal, Exactly(int64(11)))
 
 	newVal, err = rdb.Decr(ctx, "counter").Result()
 	if err != nil {
 		panic("Failed to decrement counter: " + err.Error())
 	}
 	fmt.Printf("Counter after decrement: %d\n", newVal)
+	expect("DECR", "counter after decrement", newVal, Exactly(int64(10)))
 
 	// Append
 	fmt.Println("\n4. APPEND operation:")
@@ -72,6 +77,8 @@ func RunStringExamples(rdb *redis.Client) {
 
 	finalMsg, _ := rdb.Get(ctx, "message").Result()
 	fmt.Printf(" Appended message: %s (length: %d)\n", finalMsg, length)
+	expect("APPEND", "message length", length, Exactly(int64(12)))
+	expect("GET", "appended message", finalMsg, Exactly("Hello World!"))
 
 	// MSET and MGET (Multiple operations)
 	fmt.Println("\n5. Multiple SET and GET:")
@@ -85,6 +92,7 @@ func RunStringExamples(rdb *redis.Client) {
 		panic("Failed to get multiple values: " + err.Error())
 	}
 
+	expect("MGET", "key1..key3 values", values, Exactly([]interface{}{"value1", "value2", "value3"}))
 	for i, val := range values {
 		if val == nil {
 			fmt.Printf(" key%d = <nil>\n", i+1)
@@ -100,6 +108,7 @@ func RunStringExamples(rdb *redis.Client) {
 		panic("Failed to check key existence: " + err.Error())
 	}
 	fmt.Printf(" EXISTS user:1 = %d\n", exists)
+	expect("EXISTS", "user:1 exists", exists, Exactly(int64(1)))
 
 	// DEL - Delete keys
 	fmt.Println("\n7. Cleanup:")
@@ -108,6 +117,7 @@ func RunStringExamples(rdb *redis.Client) {
 		panic("Failed to delete keys: " + err.Error())
 	}
 	fmt.Printf(" Deleted keys: %d\n", deleted)
+	expect("DEL", "deleted key count", deleted, Exactly(int64(4)))
 
 	// Clean up
 	fmt.Println("\n8. Cleanup all example keys:")
@@ -0,0 +1,79 @@
+package examples
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fastScale is how much fast mode shrinks example TTLs, so 10s becomes 100ms
+const fastScale = 100
+
+// expiryTimeout bounds how long fast mode polls for a key to expire
+const expiryTimeout = time.Second
+
+var fastMode bool
+
+// SetFastMode scales every example TTL down to milliseconds and replaces
+// fixed sleeps with polling for expiry
+func SetFastMode(on bool) {
+	fastMode = on
+}
+
+// scaled returns d, or d shrunk by fastScale in fast mode
+func scaled(d time.Duration) time.Duration {
+	if fastMode {
+		return d / fastScale
+	}
+	return d
+}
+
+// scaledAtLeast returns scaled(d), but no less than min, for timings that
+// stop showing anything once they get close to a round trip
+func scaledAtLeast(d, min time.Duration) time.Duration {
+	if s := scaled(d); s > min {
+		return s
+	}
+	return min
+}
+
+// expire sets a key's TTL with EXPIRE, or PEXPIRE in fast mode
+func expire(ctx context.Context, rdb *redis.Client, key string, d time.Duration) *redis.BoolCmd {
+	if fastMode {
+		return rdb.PExpire(ctx, key, scaled(d))
+	}
+	return rdb.Expire(ctx, key, d)
+}
+
+// commandName returns the name of the command cmd sent, for checks on
+// commands that change with fast mode
+func commandName(cmd redis.Cmder) string {
+	return strings.ToUpper(cmd.Name())
+}
+
+// keyTTL reads a key's TTL with TTL, or PTTL in fast mode
+func keyTTL(ctx context.Context, rdb *redis.Client, key string) *redis.DurationCmd {
+	if fastMode {
+		return rdb.PTTL(ctx, key)
+	}
+	return rdb.TTL(ctx, key)
+}
+
+// waitForExpiry sleeps for d, or in fast mode polls until key is gone
+func waitForExpiry(ctx context.Context, rdb *redis.Client, key string, d time.Duration) {
+	if !fastMode {
+		time.Sleep(d)
+		return
+	}
+
+	deadline := time.Now().Add(scaled(d) + expiryTimeout)
+	for time.Now().Before(deadline) {
+		n, err := rdb.Exists(ctx, key).Result()
+		if err != nil || n == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,170 @@
+package examples
+
+import (
+	"context"
+	"fmt"
+	"redis-playground/nearcache"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunClientTrackingExamples demonstrates server-assisted client-side
+// caching with the nearcache package
+func RunClientTrackingExamples(rdb *redis.Client) {
+	fmt.Println("\n Client-Side Caching (CLIENT TRACKING)")
+	fmt.Println("=========================================")
+
+	ctx := context.Background()
+
+	// CLIENT TRACKING was added in Redis 6.0
+	version, major, err := serverMajorVersion(ctx, rdb)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if major < 6 {
+		fmt.Printf("   Server is version %s, CLIENT TRACKING needs 6.0 or newer, skipping\n", version)
+		return
+	}
+
+	// 1. Default mode over RESP3 - Redis remembers what we read and pushes
+	// an invalidation on the same connection when it changes
+	fmt.Println("1. Default mode, invalidations pushed over RESP3:")
+	resp3, err := nearcache.New(ctx, rdb, nearcache.Options{Hooks: exampleHooks()})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer resp3.Close()
+	if !runTrackedKey(ctx, rdb, resp3, "tracking:resp3:user:42") {
+		return
+	}
+
+	// 2. Default mode over RESP2 - pushes need RESP3, so invalidations are
+	// redirected to a second connection subscribed to __redis__:invalidate
+	fmt.Println("\n2. Default mode, invalidations redirected to __redis__:invalidate (RESP2):")
+	resp2, err := nearcache.New(ctx, rdb, nearcache.Options{Protocol: 2, Hooks: exampleHooks()})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer resp2.Close()
+	if !runTrackedKey(ctx, rdb, resp2, "tracking:resp2:user:42") {
+		return
+	}
+
+	// 3. Broadcast mode - Redis keeps no per-key state, it reports every
+	// change under the prefixes whether we read the key or not
+	fmt.Println("\n3. Broadcast mode with PREFIX tracking:bcast:")
+	bcast, err := nearcache.New(ctx, rdb, nearcache.Options{Broadcast: true, Prefixes: []string{"tracking:bcast:"}, Hooks: exampleHooks()})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer bcast.Close()
+	before := bcast.Stats().Invalidations
+	rdb.Set(ctx, "tracking:bcast:never-read", "1", 0)
+	invalidated := waitForInvalidation(bcast, before)
+	fmt.Printf("   SET on a key we never read -> invalidation received: %v\n", invalidated)
+	expect("CLIENT TRACKING BCAST", "invalidation for an unread key", invalidated, Exactly(true))
+
+	rdb.Set(ctx, "tracking:other", "outside the prefix", 0)
+	for i := 0; i < 3; i++ {
+		bcast.Get(ctx, "tracking:other")
+	}
+	stats := bcast.Stats()
+	fmt.Printf("   3 reads of a key outside the prefix: %d hits, %d misses (never cached, no invalidations would come)\n", stats.Hits, stats.Misses)
+	expect("CLIENT TRACKING BCAST", "reads outside the prefix", stats.Misses, Exactly(int64(3)))
+
+	// 4. Hit rate - a read-heavy workload with occasional writes from
+	// another connection
+	fmt.Println("\n4. Hit rate for a read-heavy workload:")
+	hot, err := nearcache.New(ctx, rdb, nearcache.Options{Hooks: exampleHooks()})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer hot.Close()
+	const keys, reads, writeEvery = 10, 2000, 100
+	for i := 0; i < keys; i++ {
+		rdb.Set(ctx, fmt.Sprintf("tracking:hot:%d", i), i, 0)
+	}
+	start := time.Now()
+	for i := 0; i < reads; i++ {
+		key := fmt.Sprintf("tracking:hot:%d", i%keys)
+		if i%writeEvery == writeEvery-1 {
+			rdb.Incr(ctx, fmt.Sprintf("tracking:hot:%d", i/writeEvery%keys))
+		}
+		if _, err := hot.Get(ctx, key); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+	}
+	elapsed := time.Since(start)
+	stats = hot.Stats()
+	hitRate := float64(stats.Hits) / float64(stats.Hits+stats.Misses) * 100
+	fmt.Printf("   %d reads over %d keys, a write every %d reads, took %v\n", reads, keys, writeEvery, elapsed.Round(time.Millisecond))
+	fmt.Printf("   local hits %d, reads from Redis %d, invalidations %d, hit rate %.1f%%\n",
+		stats.Hits, stats.Misses, stats.Invalidations, hitRate)
+	expect("GET", "near cache hit rate", hitRate, InRange(80, 100))
+
+	// Cleanup
+	var cleanup []string
+	for i := 0; i < keys; i++ {
+		cleanup = append(cleanup, fmt.Sprintf("tracking:hot:%d", i))
+	}
+	rdb.Del(ctx, append(cleanup, "tracking:resp3:user:42", "tracking:resp2:user:42",
+		"tracking:bcast:never-read", "tracking:other")...)
+	fmt.Println("\n5. Cleanup: Cleaned up client tracking examples ✓")
+}
+
+// runTrackedKey reads key through nc until it is cached, changes it from
+// another connection and shows the local copy being invalidated, returning
+// false if the example had to stop early
+func runTrackedKey(ctx context.Context, rdb *redis.Client, nc *nearcache.Cache, key string) bool {
+	if err := rdb.Set(ctx, key, "Naim", 0).Err(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := nc.Get(ctx, key); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return false
+		}
+	}
+	stats := nc.Stats()
+	fmt.Printf("   5 reads of %s: %d from Redis, %d from local memory\n", key, stats.Misses, stats.Hits)
+	expect("GET", "local hits after the first read", stats.Hits, Exactly(int64(4)))
+
+	// rdb uses its own pooled connections, like another application server
+	before := stats.Invalidations
+	rdb.Set(ctx, key, "Ada", 0)
+	invalidated := waitForInvalidation(nc, before)
+	fmt.Printf("   Another connection SETs %s -> local entry invalidated: %v (%d keys cached)\n", key, invalidated, nc.Len())
+	expect("CLIENT TRACKING", "invalidation after a write", invalidated, Exactly(true))
+
+	val, err := nc.Get(ctx, key)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return false
+	}
+	nc.Get(ctx, key)
+	stats = nc.Stats()
+	fmt.Printf("   Next reads see %q, hit rate %d/%d\n", val, stats.Hits, stats.Hits+stats.Misses)
+	expect("GET", "value after invalidation", val, Exactly("Ada"))
+	return true
+}
+
+// waitForInvalidation waits up to a second for nc to count more
+// invalidations than before
+func waitForInvalidation(nc *nearcache.Cache, before int64) bool {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if nc.Stats().Invalidations > before {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
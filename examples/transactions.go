@@ -0,0 +1,171 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RunTransactionExamples demonstrates MULTI/EXEC and WATCH optimistic locking
+func RunTransactionExamples(rdb *redis.Client) {
+	fmt.Println("\n Transaction Operations")
+	fmt.Println("=========================")
+
+	ctx := context.Background()
+	counter := "tx:counter"
+
+	const workers, perWorker = 10, 50
+	want := int64(workers * perWorker)
+
+	// GET + SET - Read-modify-write from many goroutines
+	fmt.Println("1. Read-modify-write with GET and SET (no transaction):")
+	rdb.Set(ctx, counter, 0, 0)
+	elapsed := concurrently(workers, perWorker, func() error {
+		n, err := rdb.Get(ctx, counter).Int64()
+		if err != nil {
+			return err
+		}
+		// Another goroutine can write between our GET and SET
+		time.Sleep(100 * time.Microsecond)
+		return rdb.Set(ctx, counter, n+1, 0).Err()
+	})
+	got, _ := rdb.Get(ctx, counter).Int64()
+	fmt.Printf("   %d goroutines x %d increments: counter = %d, %d updates lost (%v)\n",
+		workers, perWorker, got, want-got, elapsed.Round(time.Millisecond))
+
+	// INCR - The server does the read-modify-write in one step
+	fmt.Println("\n2. The same with INCR, as in the string examples:")
+	rdb.Set(ctx, counter, 0, 0)
+	elapsed = concurrently(workers, perWorker, func() error {
+		return rdb.Incr(ctx, counter).Err()
+	})
+	got, _ = rdb.Get(ctx, counter).Int64()
+	fmt.Printf("   counter = %d, no updates lost (%v)\n", got, elapsed.Round(time.Millisecond))
+	expect("INCR", "concurrent increments", got, Exactly(want))
+
+	// WATCH / MULTI / EXEC - Optimistic locking with retries
+	fmt.Println("\n3. Read-modify-write with WATCH, MULTI and EXEC:")
+	rdb.Set(ctx, counter, 0, 0)
+	var retries, gaveUp atomic.Int64
+	elapsed = concurrently(workers, perWorker, func() error {
+		n, err := watchedIncr(ctx, rdb, counter, 100)
+		retries.Add(int64(n))
+		if errors.Is(err, redis.TxFailedErr) {
+			gaveUp.Add(1)
+		}
+		return err
+	})
+	got, _ = rdb.Get(ctx, counter).Int64()
+	fmt.Printf("   counter = %d, %d conflicts retried, %d gave up (%v)\n",
+		got, retries.Load(), gaveUp.Load(), elapsed.Round(time.Millisecond))
+	fmt.Println("   EXEC fails when a WATCHed key changed, so the loser re-reads and tries again")
+	expect("WATCH", "concurrent watched increments", got, Exactly(want-gaveUp.Load()))
+
+	// TxPipelined - Queue several commands and run them atomically
+	fmt.Println("\n4. Atomic transfers with TxPipelined:")
+	alice, bob := "tx:balance:alice", "tx:balance:bob"
+	rdb.MSet(ctx, alice, 100, bob, 50)
+
+	var fromAlice, toBob *redis.IntCmd
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		fromAlice = pipe.DecrBy(ctx, alice, 30)
+		toBob = pipe.IncrBy(ctx, bob, 30)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("   MULTI, DECRBY alice 30, INCRBY bob 30, EXEC -> alice = %d, bob = %d\n", fromAlice.Val(), toBob.Val())
+	fmt.Println("   No other client can see alice debited but bob not yet credited")
+	expect("MULTI", "balances after transfer", []int64{fromAlice.Val(), toBob.Val()}, Exactly([]int64{70, 80}))
+
+	// EXEC does not roll back - A command failing at run time leaves the rest applied
+	fmt.Println("\n5. EXEC does not roll back failed commands:")
+	greeting, after := "tx:greeting", "tx:after"
+	cmds, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, greeting, "hello", 0)
+		pipe.Incr(ctx, greeting) // queues fine, fails inside EXEC
+		pipe.Set(ctx, after, "still written", 0)
+		return nil
+	})
+	fmt.Printf("   TxPipelined returned: %v\n", err)
+	for _, cmd := range cmds {
+		fmt.Printf("     %s\n", cmd)
+	}
+	afterVal, _ := rdb.Get(ctx, after).Result()
+	fmt.Printf("   GET %s = %q, the commands around the failed INCR were applied\n", after, afterVal)
+	expect("EXEC", "command after runtime error applied", afterVal, Exactly("still written"))
+
+	// A command rejected while queueing (here a wrong number of arguments)
+	// makes the server discard the whole transaction at EXEC instead
+	rdb.Del(ctx, after)
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, after, "never written", 0)
+		pipe.Do(ctx, "SET", greeting)
+		return nil
+	})
+	fmt.Printf("   With a malformed command queued, EXEC fails: %v\n", err)
+	exists, _ := rdb.Exists(ctx, after).Result()
+	fmt.Printf("   EXISTS %s = %d, nothing was applied\n", after, exists)
+	expect("EXEC", "transaction discarded after queue error", exists, Exactly(int64(0)))
+	fmt.Println("   Validate inputs before EXEC, there is no ROLLBACK command")
+
+	// Cleanup
+	fmt.Println("\n6. Cleanup:")
+	rdb.Del(ctx, counter, alice, bob, greeting, after)
+	fmt.Println("   Cleaned up transaction examples ✓")
+}
+
+// watchedIncr increments key with WATCH/MULTI/EXEC, retrying up to attempts
+// times when another client changes the key first. It returns the number of
+// retries it needed.
+func watchedIncr(ctx context.Context, rdb *redis.Client, key string, attempts int) (int, error) {
+	var err error
+	for i := 0; i < attempts; i++ {
+		err = rdb.Watch(ctx, func(tx *redis.Tx) error {
+			n, err := tx.Get(ctx, key).Int64()
+			if err != nil && err != redis.Nil {
+				return err
+			}
+			time.Sleep(100 * time.Microsecond)
+
+			// EXEC returns redis.TxFailedErr if key changed since WATCH
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, n+1, 0)
+				return nil
+			})
+			return err
+		}, key)
+		if err != redis.TxFailedErr {
+			return i, err
+		}
+	}
+	return attempts, err
+}
+
+// concurrently runs fn perWorker times in each of workers goroutines and
+// returns how long it took. Errors are printed once.
+func concurrently(workers, perWorker int, fn func() error) time.Duration {
+	start := time.Now()
+	var once sync.Once
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				if err := fn(); err != nil {
+					once.Do(func() { fmt.Printf("   Error: %v\n", err) })
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return time.Since(start)
+}
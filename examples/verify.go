@@ -0,0 +1,349 @@
+package examples
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Expectation describes the result a step is expected to produce
+type Expectation interface {
+	Matches(actual interface{}) bool
+	String() string
+}
+
+// Check is the outcome of a single expectation
+type Check struct {
+	Example  string `json:"example"`
+	Command  string `json:"command,omitempty"`
+	Step     string `json:"step"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	Passed   bool   `json:"passed"`
+}
+
+// Example is a runnable playground example
+type Example struct {
+	Name string
+	Run  func(rdb *redis.Client)
+}
+
+// Verifiable lists the examples that declare expected results
+var Verifiable = []Example{
+	{Name: "strings", Run: RunStringExamples},
+	{Name: "lists", Run: RunListExamples},
+	{Name: "sets", Run: RunSetsExamples},
+	{Name: "sorted_sets", Run: RunSortedSetsExamples},
+	{Name: "hashes", Run: RunHashesExamples},
+	{Name: "expiration_ttl", Run: RunExpirationTTLExamples},
+	{Name: "caching", Run: RunCachingExamples},
+	{Name: "pubsub", Run: RunPubSub},
+	{Name: "streams", Run: RunStreamsExamples},
+	{Name: "hyperloglog", Run: RunHyperLogLogExamples},
+	{Name: "bitmaps", Run: RunBitmapExamples},
+	{Name: "geo", Run: RunGeoExamples},
+	{Name: "transactions", Run: RunTransactionExamples},
+	{Name: "scripting", Run: RunScriptingExamples},
+	{Name: "functions", Run: RunFunctionExamples},
+	{Name: "pipelining", Run: RunPipeliningExamples},
+	{Name: "locks", Run: RunLockExamples},
+	{Name: "ratelimits", Run: RunRateLimitExamples},
+	{Name: "tracking", Run: RunClientTrackingExamples},
+	{Name: "keyspace", Run: RunKeyspaceNotificationExamples},
+	{Name: "queues", Run: RunReliableQueueExamples},
+	{Name: "scheduling", Run: RunSchedulerExamples},
+	{Name: "blocking", Run: RunBlockingExamples},
+}
+
+var verifier struct {
+	sync.Mutex
+	enabled bool
+	example string
+	checks  []Check
+}
+
+// Verify runs every verifiable example with expectations enabled and
+// returns all recorded checks
+func Verify(rdb *redis.Client) []Check {
+	verifier.Lock()
+	verifier.enabled = true
+	verifier.checks = nil
+	verifier.Unlock()
+
+	defer func() {
+		verifier.Lock()
+		verifier.enabled = false
+		verifier.example = ""
+		verifier.Unlock()
+	}()
+
+	for _, ex := range Verifiable {
+		runVerified(rdb, ex)
+	}
+
+	verifier.Lock()
+	defer verifier.Unlock()
+	return verifier.checks
+}
+
+// runVerified runs one example and records a failed check if it panics
+func runVerified(rdb *redis.Client, ex Example) {
+	verifier.Lock()
+	verifier.example = ex.Name
+	verifier.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			record(Check{
+				Example:  ex.Name,
+				Step:     "example completes",
+				Expected: "no panic",
+				Actual:   fmt.Sprint(r),
+			})
+		}
+	}()
+	ex.Run(rdb)
+}
+
+// PrintVerifyReport prints the mismatches in checks and reports whether all passed
+func PrintVerifyReport(checks []Check) bool {
+	fmt.Println("\n Verification Report")
+	fmt.Println("=====================")
+
+	failed := 0
+	for _, c := range checks {
+		if c.Passed {
+			continue
+		}
+		failed++
+		fmt.Printf("   ✗ [%s] %s %s\n", c.Example, c.Command, c.Step)
+		fmt.Printf("       expected: %s\n", c.Expected)
+		fmt.Printf("       actual:   %s\n", c.Actual)
+	}
+
+	fmt.Printf("   %d checks, %d passed, %d failed\n", len(checks), len(checks)-failed, failed)
+	return failed == 0
+}
+
+// expect records whether actual satisfies want when verify mode is on
+func expect(command, step string, actual interface{}, want Expectation) {
+	verifier.Lock()
+	enabled, example := verifier.enabled, verifier.example
+	verifier.Unlock()
+	if !enabled {
+		return
+	}
+
+	record(Check{
+		Example:  example,
+		Command:  command,
+		Step:     step,
+		Expected: want.String(),
+		Actual:   fmt.Sprintf("%v", actual),
+		Passed:   want.Matches(actual),
+	})
+}
+
+func record(c Check) {
+	verifier.Lock()
+	verifier.checks = append(verifier.checks, c)
+	verifier.Unlock()
+
+	if !c.Passed {
+		fmt.Printf("   ✗ %s: expected %s, got %s\n", c.Step, c.Expected, c.Actual)
+	}
+}
+
+// Exactly expects a value deeply equal to want
+func Exactly(want interface{}) Expectation {
+	return exactly{want: want}
+}
+
+type exactly struct{ want interface{} }
+
+func (e exactly) Matches(actual interface{}) bool { return reflect.DeepEqual(actual, e.want) }
+func (e exactly) String() string                  { return fmt.Sprintf("%v", e.want) }
+
+// SameMembers expects a []string holding exactly members, in any order
+func SameMembers(members ...string) Expectation {
+	return sameMembers(members)
+}
+
+type sameMembers []string
+
+func (s sameMembers) Matches(actual interface{}) bool {
+	got, ok := actual.([]string)
+	if !ok || len(got) != len(s) {
+		return false
+	}
+	return reflect.DeepEqual(sorted(got), sorted(s))
+}
+
+func (s sameMembers) String() string { return fmt.Sprintf("%v (any order)", sorted(s)) }
+
+// OneOf expects a string equal to one of values
+func OneOf(values ...string) Expectation {
+	return oneOf(values)
+}
+
+type oneOf []string
+
+func (o oneOf) Matches(actual interface{}) bool {
+	got, ok := actual.(string)
+	if !ok {
+		return false
+	}
+	for _, v := range o {
+		if v == got {
+			return true
+		}
+	}
+	return false
+}
+
+func (o oneOf) String() string { return "one of " + strings.Join(o, ", ") }
+
+// SampleOf expects a []string of n distinct values drawn from members
+func SampleOf(n int, members ...string) Expectation {
+	return sampleOf{n: n, members: members}
+}
+
+type sampleOf struct {
+	n       int
+	members []string
+}
+
+func (s sampleOf) Matches(actual interface{}) bool {
+	got, ok := actual.([]string)
+	if !ok || len(got) != s.n {
+		return false
+	}
+	seen := make(map[string]bool)
+	for _, v := range got {
+		if seen[v] || !oneOf(s.members).Matches(v) {
+			return false
+		}
+		seen[v] = true
+	}
+	return true
+}
+
+func (s sampleOf) String() string {
+	return fmt.Sprintf("%d distinct of %v", s.n, s.members)
+}
+
+// Between expects a time.Duration within [min, max]
+func Between(min, max time.Duration) Expectation {
+	return between{min: min, max: max}
+}
+
+type between struct{ min, max time.Duration }
+
+func (b between) Matches(actual interface{}) bool {
+	got, ok := actual.(time.Duration)
+	return ok && got >= b.min && got <= b.max
+}
+
+func (b between) String() string { return fmt.Sprintf("between %v and %v", b.min, b.max) }
+
+// InRange expects a number within [min, max]
+func InRange(min, max float64) Expectation {
+	return inRange{min: min, max: max}
+}
+
+type inRange struct{ min, max float64 }
+
+func (r inRange) Matches(actual interface{}) bool {
+	var got float64
+	switch v := actual.(type) {
+	case float64:
+		got = v
+	case int64:
+		got = float64(v)
+	case int:
+		got = float64(v)
+	default:
+		return false
+	}
+	return got >= r.min && got <= r.max
+}
+
+func (r inRange) String() string { return fmt.Sprintf("between %g and %g", r.min, r.max) }
+
+func sorted(values []string) []string {
+	out := append([]string(nil), values...)
+	sort.Strings(out)
+	return out
+}
+
+// zsetModel mirrors a sorted set in memory so expectations can be derived
+// from whatever data an example loaded
+type zsetModel map[string]float64
+
+func newZSetModel(entries []redis.Z) zsetModel {
+	m := make(zsetModel, len(entries))
+	for _, z := range entries {
+		m[fmt.Sprint(z.Member)] = z.Score
+	}
+	return m
+}
+
+// ascending returns the entries ordered like ZRANGE: by score, then member
+func (m zsetModel) ascending() []redis.Z {
+	out := make([]redis.Z, 0, len(m))
+	for member, score := range m {
+		out = append(out, redis.Z{Score: score, Member: member})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score < out[j].Score
+		}
+		return out[i].Member.(string) < out[j].Member.(string)
+	})
+	return out
+}
+
+// descending returns the entries ordered like ZREVRANGE
+func (m zsetModel) descending() []redis.Z {
+	asc := m.ascending()
+	out := make([]redis.Z, len(asc))
+	for i, z := range asc {
+		out[len(asc)-1-i] = z
+	}
+	return out
+}
+
+// byScore returns the entries with min <= score <= max, like ZRANGEBYSCORE
+func (m zsetModel) byScore(min, max float64) []redis.Z {
+	out := []redis.Z{}
+	for _, z := range m.ascending() {
+		if z.Score >= min && z.Score <= max {
+			out = append(out, z)
+		}
+	}
+	return out
+}
+
+// rank returns the 0-based position of member in ascending order
+func (m zsetModel) rank(member string) int64 {
+	for i, z := range m.ascending() {
+		if z.Member == member {
+			return int64(i)
+		}
+	}
+	return -1
+}
+
+// top returns the first n entries of ZREVRANGE
+func (m zsetModel) top(n int) []redis.Z {
+	desc := m.descending()
+	if len(desc) > n {
+		desc = desc[:n]
+	}
+	return desc
+}
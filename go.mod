@@ -2,7 +2,12 @@ module redis-playground
 
 go 1.22.5
 
-require github.com/redis/go-redis/v9 v9.12.0
+require (
+	github.com/alicebob/miniredis/v2 v2.39.0
+	github.com/redis/go-redis/v9 v9.12.0
+)
+
+require github.com/yuin/gopher-lua v1.1.1 // indirect
 
 require (
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
@@ -0,0 +1,254 @@
+// Package lock implements a Redis lock for a single instance. A lock is
+// acquired with SET NX PX and a random token, released with a
+// compare-and-delete script so only the holder can release it, and kept
+// alive by a watchdog that renews the lease while it is held. Every
+// acquisition also gets a fencing token from INCR, which increases
+// monotonically so a resource can reject writes from a holder whose lease
+// has already expired.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	mathrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotAcquired is returned by TryAcquire when someone else holds the lock
+var ErrNotAcquired = errors.New("lock: held by someone else")
+
+// ErrNotHeld is returned when releasing or refreshing a lock whose lease
+// expired, possibly after another holder acquired it
+var ErrNotHeld = errors.New("lock: no longer held")
+
+// acquireScript sets the lock and issues a fencing token in one step, so
+// tokens increase in the same order locks are granted
+var acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+  return redis.call('INCR', KEYS[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock only if it still holds our token
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// refreshScript extends the lease only if it still holds our token
+var refreshScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Options configures a Locker
+type Options struct {
+	// Prefix is prepended to lock names to form keys, it defaults to "lock:"
+	Prefix string
+	// TTL is the lease length, it defaults to 10s and is rounded up to 1ms,
+	// the shortest lease Redis accepts
+	TTL time.Duration
+	// DisableRenew turns off the watchdog, so the lease simply runs out
+	DisableRenew bool
+	// RenewInterval is how often the watchdog extends the lease, it
+	// defaults to TTL/3
+	RenewInterval time.Duration
+	// RetryMin and RetryMax bound the jittered exponential backoff used by
+	// Acquire between attempts, they default to 10ms and 500ms
+	RetryMin time.Duration
+	RetryMax time.Duration
+}
+
+// Locker acquires locks on a client
+type Locker struct {
+	rdb  *redis.Client
+	opts Options
+}
+
+// New returns a Locker for opts, filling in defaults for unset fields
+func New(rdb *redis.Client, opts Options) *Locker {
+	if opts.Prefix == "" {
+		opts.Prefix = "lock:"
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 10 * time.Second
+	}
+	opts.TTL = max(opts.TTL, time.Millisecond)
+	if opts.RenewInterval <= 0 {
+		opts.RenewInterval = opts.TTL / 3
+	}
+	if opts.RetryMin <= 0 {
+		opts.RetryMin = 10 * time.Millisecond
+	}
+	if opts.RetryMax < opts.RetryMin {
+		opts.RetryMax = max(500*time.Millisecond, opts.RetryMin)
+	}
+	return &Locker{rdb: rdb, opts: opts}
+}
+
+// TryAcquire makes a single attempt, returning ErrNotAcquired if the lock
+// is held
+func (l *Locker) TryAcquire(ctx context.Context, name string) (*Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.opts.Prefix + name
+	fence, err := acquireScript.Run(ctx, l.rdb, []string{key, key + ":fence"}, token, l.opts.TTL.Milliseconds()).Int64()
+	if err != nil {
+		return nil, err
+	}
+	if fence == 0 {
+		return nil, ErrNotAcquired
+	}
+
+	lk := &Lock{
+		rdb:   l.rdb,
+		opts:  l.opts,
+		name:  name,
+		key:   key,
+		token: token,
+		fence: fence,
+		lost:  make(chan struct{}),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if l.opts.DisableRenew {
+		close(lk.done)
+	} else {
+		go lk.watchdog()
+	}
+	return lk, nil
+}
+
+// Acquire retries with jittered exponential backoff until the lock is
+// acquired or ctx is done
+func (l *Locker) Acquire(ctx context.Context, name string) (*Lock, error) {
+	backoff := l.opts.RetryMin
+	for {
+		lk, err := l.TryAcquire(ctx, name)
+		if err != ErrNotAcquired {
+			return lk, err
+		}
+
+		// Full jitter keeps contending clients from retrying in lockstep
+		wait := time.Duration(mathrand.Int63n(int64(backoff)) + 1)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, l.opts.RetryMax)
+	}
+}
+
+// Lock is a held lock
+type Lock struct {
+	rdb   *redis.Client
+	opts  Options
+	name  string
+	key   string
+	token string
+	fence int64
+
+	lost     chan struct{}
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Name returns the lock name
+func (lk *Lock) Name() string { return lk.name }
+
+// Token returns the random token identifying this holder
+func (lk *Lock) Token() string { return lk.token }
+
+// Fence returns the fencing token, larger than that of every earlier holder
+func (lk *Lock) Fence() int64 { return lk.fence }
+
+// Lost is closed when the watchdog finds the lease gone or cannot renew it
+// before it expires. Work protected by the lock should stop. Without the
+// watchdog it is never closed, check Refresh instead.
+func (lk *Lock) Lost() <-chan struct{} { return lk.lost }
+
+// Refresh extends the lease to ttl from now, rounding it up to 1ms
+func (lk *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	if ttl <= 0 {
+		return errors.New("lock: refresh TTL must be positive")
+	}
+	ttl = max(ttl, time.Millisecond)
+	ok, err := refreshScript.Run(ctx, lk.rdb, []string{lk.key}, lk.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// Release stops the watchdog and deletes the lock if we still hold it,
+// returning ErrNotHeld if the lease had already expired
+func (lk *Lock) Release(ctx context.Context) error {
+	lk.stopOnce.Do(func() { close(lk.stop) })
+	<-lk.done
+
+	n, err := releaseScript.Run(ctx, lk.rdb, []string{lk.key}, lk.token).Int64()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// watchdog renews the lease every RenewInterval until Release, and
+// reports the lock lost if the lease is gone or expires while Redis is
+// unreachable
+func (lk *Lock) watchdog() {
+	defer close(lk.done)
+	ticker := time.NewTicker(lk.opts.RenewInterval)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-lk.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), lk.opts.RenewInterval)
+		err := lk.Refresh(ctx, lk.opts.TTL)
+		cancel()
+
+		switch {
+		case err == nil:
+			renewed = time.Now()
+		case err == ErrNotHeld || time.Since(renewed) >= lk.opts.TTL:
+			close(lk.lost)
+			return
+		}
+		// Other errors are retried on the next tick while the lease lasts
+	}
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -3,14 +3,41 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"os"
+	"redis-playground/chat"
+	"redis-playground/compat"
 	"redis-playground/config"
 	"redis-playground/examples"
+	"redis-playground/seed"
 	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
+	fast := flag.Bool("fast", false, "scale example TTLs down to milliseconds and poll for expiry")
+	benchOps := flag.Int("bench-ops", 1000, "operations per mode in the pipelining benchmark")
+	benchLatency := flag.Duration("bench-latency", 0, "latency the pipelining benchmark adds to every round trip, e.g. 1ms")
+	flag.Func("dataset", "load an example dataset from a JSON or CSV file, as name=path\n(names: leaderboard, readings, profiles, tags)", func(v string) error {
+		name, path, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("expected name=path, got %q", v)
+		}
+		return examples.UseDataset(name, path)
+	})
+	flag.Parse()
+	examples.SetFastMode(*fast)
+	examples.SetBenchmarkOptions(*benchOps, *benchLatency)
+
+	// The compatibility report brings its own endpoints
+	if flag.Arg(0) == "compat" {
+		runCompat(flag.Args()[1:])
+		return
+	}
+
 	// Initialize Redis client
 	rdb := config.InitRedis()
 	defer rdb.Close()
@@ -22,6 +49,23 @@ func main() {
 		panic("Failed to connect to Redis: " + err.Error())
 	}
 
+	// Non-interactive commands
+	switch flag.Arg(0) {
+	case "verify":
+		checks := examples.Verify(rdb)
+		if !examples.PrintVerifyReport(checks) {
+			rdb.Close()
+			os.Exit(1)
+		}
+		return
+	case "seed":
+		runSeed(rdb, flag.Args()[1:])
+		return
+	case "unseed":
+		runUnseed(rdb, flag.Args()[1:])
+		return
+	}
+
 	fmt.Println("Welcome to Redis Playground with Go!")
 	fmt.Println("=====================================")
 
@@ -53,6 +97,38 @@ func main() {
 			examples.RunCachingExamples(rdb)
 		case "8":
 			examples.RunPubSub(rdb)
+		case "9":
+			examples.RunStreamsExamples(rdb)
+		case "10":
+			examples.RunHyperLogLogExamples(rdb)
+		case "11":
+			examples.RunBitmapExamples(rdb)
+		case "12":
+			examples.RunGeoExamples(rdb)
+		case "13":
+			examples.RunTransactionExamples(rdb)
+		case "14":
+			examples.RunScriptingExamples(rdb)
+		case "15":
+			examples.RunFunctionExamples(rdb)
+		case "16":
+			examples.RunPipeliningExamples(rdb)
+		case "17":
+			examples.RunLockExamples(rdb)
+		case "18":
+			examples.RunRateLimitExamples(rdb)
+		case "19":
+			examples.RunClientTrackingExamples(rdb)
+		case "20":
+			examples.RunKeyspaceNotificationExamples(rdb)
+		case "21":
+			runChat(rdb, scanner)
+		case "22":
+			examples.RunReliableQueueExamples(rdb)
+		case "23":
+			examples.RunSchedulerExamples(rdb)
+		case "24":
+			examples.RunBlockingExamples(rdb)
 		case "0":
 			fmt.Println("Exiting Redis Playground. Goodbye!")
 			return
@@ -75,5 +151,262 @@ func showMenu() {
 	fmt.Println("6. Run Expiration & TTL Examples")
 	fmt.Println("7. Run Caching Examples")
 	fmt.Println("8. Run Pub/Sub Examples")
+	fmt.Println("9. Run Stream Examples")
+	fmt.Println("10. Run HyperLogLog Examples")
+	fmt.Println("11. Run Bitmap Examples")
+	fmt.Println("12. Run Geospatial Examples")
+	fmt.Println("13. Run Transaction Examples")
+	fmt.Println("14. Run Lua Scripting Examples")
+	fmt.Println("15. Run Redis Functions Examples")
+	fmt.Println("16. Run Pipelining Benchmark")
+	fmt.Println("17. Run Distributed Lock Examples")
+	fmt.Println("18. Run Rate Limiting Examples")
+	fmt.Println("19. Run Client-Side Caching Examples")
+	fmt.Println("20. Run Keyspace Notification Examples")
+	fmt.Println("21. Chat (run the playground in several terminals)")
+	fmt.Println("22. Run Reliable Queue Examples")
+	fmt.Println("23. Run Delayed Job Scheduler Examples")
+	fmt.Println("24. Run Blocking Operations Examples")
 	fmt.Println("0. Exit")
 }
+
+func runCompat(args []string) {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	out := fs.String("o", "compat-report", "report file prefix, writes <prefix>.md and <prefix>.json")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Println("Usage: compat [-o prefix] addr [addr...]")
+		os.Exit(2)
+	}
+
+	report := compat.Run(context.Background(), fs.Args())
+
+	for ext, write := range map[string]func(*os.File) error{
+		".md":   func(f *os.File) error { return report.WriteMarkdown(f) },
+		".json": func(f *os.File) error { return report.WriteJSON(f) },
+	} {
+		f, err := os.Create(*out + ext)
+		if err != nil {
+			panic("Failed to create report: " + err.Error())
+		}
+		err = write(f)
+		f.Close()
+		if err != nil {
+			panic("Failed to write report: " + err.Error())
+		}
+		fmt.Printf("Report written to %s\n", *out+ext)
+	}
+}
+
+func runSeed(rdb *redis.Client, args []string) {
+	opts := seed.DefaultOptions()
+
+	fs := flag.NewFlagSet("seed", flag.ExitOnError)
+	fs.IntVar(&opts.Users, "users", opts.Users, "number of users")
+	fs.IntVar(&opts.PostsPerUser, "posts", opts.PostsPerUser, "posts per user")
+	fs.IntVar(&opts.FollowsPerUser, "follows", opts.FollowsPerUser, "follows per user")
+	fs.IntVar(&opts.FeedLength, "feed", opts.FeedLength, "feed entries per user")
+	fs.IntVar(&opts.TTLPercent, "ttl-percent", opts.TTLPercent, "percentage of posts and feeds that expire")
+	fs.DurationVar(&opts.TTL, "ttl", opts.TTL, "expiry for keys that expire")
+	fs.Int64Var(&opts.Seed, "seed", opts.Seed, "random seed, the same seed produces the same data")
+	fs.StringVar(&opts.Prefix, "prefix", opts.Prefix, "prefix for every created key")
+	fs.StringVar(&opts.Schema.User, "user-key", opts.Schema.User, "key pattern for user hashes")
+	fs.StringVar(&opts.Schema.Post, "post-key", opts.Schema.Post, "key pattern for post strings")
+	fs.StringVar(&opts.Schema.Follows, "follows-key", opts.Schema.Follows, "key pattern for follow sets")
+	fs.StringVar(&opts.Schema.Feed, "feed-key", opts.Schema.Feed, "key pattern for feed lists")
+	fs.StringVar(&opts.Schema.Scores, "scores-key", opts.Schema.Scores, "key name for the score sorted set")
+	fs.IntVar(&opts.BatchSize, "batch", opts.BatchSize, "users written per pipeline")
+	fs.Parse(args)
+
+	fmt.Printf("Seeding %d users under %q (seed %d)\n", opts.Users, opts.Prefix, opts.Seed)
+	stats, err := seed.Seed(context.Background(), rdb, opts, printProgress("Seeding users"))
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		rdb.Close()
+		os.Exit(1)
+	}
+	fmt.Printf("Created %d keys (%d with TTL) in %v\n", stats.Keys, stats.Expiring, stats.Duration)
+}
+
+func runUnseed(rdb *redis.Client, args []string) {
+	fs := flag.NewFlagSet("unseed", flag.ExitOnError)
+	prefix := fs.String("prefix", seed.DefaultOptions().Prefix, "prefix the data was seeded under")
+	fs.Parse(args)
+
+	stats, err := seed.Unseed(context.Background(), rdb, *prefix, 0, printProgress("Removing keys"))
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		rdb.Close()
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted %d keys in %v\n", stats.Keys, stats.Duration)
+}
+
+// printProgress returns a seed.Progress that redraws a one-line progress bar
+func printProgress(label string) seed.Progress {
+	return func(done, total int) {
+		const width = 30
+		filled := 0
+		if total > 0 {
+			filled = done * width / total
+		}
+		fmt.Printf("\r%s [%s%s] %d/%d", label,
+			strings.Repeat("#", filled), strings.Repeat(".", width-filled), done, total)
+	}
+}
+
+const chatHelp = `Commands:
+  /join <room>     join a room and make it current
+  /leave [room]    leave the current or given room
+  /who [room]      list who is present
+  /history [n]     show the last n messages of the current room
+  /rooms           list joined rooms
+  /quit            back to the menu
+Anything else is sent to the current room.`
+
+// runChat is an interactive chat between playground instances connected to
+// the same server
+func runChat(rdb *redis.Client, scanner *bufio.Scanner) {
+	ctx := context.Background()
+
+	var session *chat.Session
+	for session == nil {
+		fmt.Print("Nickname: ")
+		if !scanner.Scan() {
+			return
+		}
+		var err error
+		session, err = chat.Connect(ctx, rdb, scanner.Text(), chat.Options{})
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+	}
+
+	// Print incoming messages while the main loop reads input
+	var printer sync.WaitGroup
+	printer.Add(1)
+	go func() {
+		defer printer.Done()
+		for m := range session.Messages() {
+			if m.Nick == session.Nick() && m.Kind != chat.KindSystem {
+				continue // our own input is already on screen
+			}
+			printChatMessage(m)
+		}
+	}()
+	defer func() {
+		if err := session.Close(ctx); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		printer.Wait()
+		fmt.Println("Left the chat.")
+	}()
+
+	current := "lobby"
+	if err := session.Join(ctx, current); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Printf("Hi %s, you are in #%s.\n%s\n", session.Nick(), current, chatHelp)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "/") {
+			if current == "" {
+				fmt.Println("You are not in a room, /join one first.")
+				continue
+			}
+			if err := session.Send(ctx, current, line); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			continue
+		}
+
+		command, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		switch command {
+		case "/join":
+			if arg == "" {
+				fmt.Println("Usage: /join <room>")
+				continue
+			}
+			if err := session.Join(ctx, arg); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			current = arg
+			fmt.Printf("You are in #%s.\n", current)
+		case "/leave":
+			room := current
+			if arg != "" {
+				room = arg
+			}
+			if err := session.Leave(ctx, room); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Left #%s.\n", room)
+			if room == current {
+				current = ""
+				if rooms := session.Rooms(); len(rooms) > 0 {
+					current = rooms[0]
+					fmt.Printf("You are in #%s.\n", current)
+				}
+			}
+		case "/who":
+			room := current
+			if arg != "" {
+				room = arg
+			}
+			nicks, err := session.Who(ctx, room)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("In #%s: %s\n", room, strings.Join(nicks, ", "))
+		case "/history":
+			if current == "" {
+				fmt.Println("You are not in a room, /join one first.")
+				continue
+			}
+			n := 10
+			if arg != "" {
+				fmt.Sscan(arg, &n)
+			}
+			messages, err := session.History(ctx, current, n)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			for _, m := range messages {
+				printChatMessage(m)
+			}
+		case "/rooms":
+			fmt.Printf("Joined: %s (current #%s)\n", strings.Join(session.Rooms(), ", "), current)
+		case "/quit":
+			return
+		default:
+			fmt.Println(chatHelp)
+		}
+	}
+}
+
+func printChatMessage(m chat.Message) {
+	at := m.Time.Format("15:04")
+	switch m.Kind {
+	case chat.KindJoin:
+		fmt.Printf("%s * %s joined #%s\n", at, m.Nick, m.Room)
+	case chat.KindLeave:
+		fmt.Printf("%s * %s left #%s\n", at, m.Nick, m.Room)
+	case chat.KindSystem:
+		fmt.Printf("%s ! %s\n", at, m.Text)
+	default:
+		fmt.Printf("%s [#%s] %s: %s\n", at, m.Room, m.Nick, m.Text)
+	}
+}
@@ -0,0 +1,333 @@
+// Package nearcache keeps hot string values in process memory and uses
+// Redis server-assisted client-side caching (CLIENT TRACKING, Redis 6+) to
+// drop them as soon as any client changes the key. Invalidations arrive
+// either as RESP3 push messages on the tracking connection itself, or with
+// RESP2 as pub/sub messages on __redis__:invalidate, redirected to a second
+// connection. In the default mode Redis remembers which keys this client
+// read, in broadcast mode it reports every change under a set of prefixes.
+package nearcache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrClosed is returned by Get after Close
+var ErrClosed = errors.New("nearcache: closed")
+
+// invalidateChannel carries invalidations in RESP2 redirect mode
+const invalidateChannel = "__redis__:invalidate"
+
+// Options configures a Cache
+type Options struct {
+	// Protocol is how invalidations are delivered: 3, the default, as RESP3
+	// pushes on the tracking connection, or 2 as pub/sub messages
+	// redirected to a second connection
+	Protocol int
+	// Broadcast turns on BCAST mode: Redis tracks prefixes instead of the
+	// keys this client read, so it keeps no per-key state for us but sends
+	// invalidations for every matching key that changes
+	Broadcast bool
+	// Prefixes limits broadcast mode to keys with these prefixes. Only those
+	// keys are cached locally, others are always read from Redis.
+	Prefixes []string
+	// MaxEntries bounds the local cache, it defaults to 10000
+	MaxEntries int
+	// Hooks see every command the cache sends on its own connections, like
+	// the hooks of a go-redis client
+	Hooks []redis.Hook
+}
+
+// Stats counts what a Cache has done so far
+type Stats struct {
+	// Hits were served from local memory, Misses went to Redis
+	Hits   int64
+	Misses int64
+	// Invalidations counts keys Redis reported as changed
+	Invalidations int64
+	// Flushes counts times the whole local cache was dropped, on FLUSHALL
+	// or when a connection failed
+	Flushes int64
+}
+
+// Cache is a local cache of string keys kept consistent by Redis
+type Cache struct {
+	opt  *redis.Options
+	opts Options
+
+	// connMu serializes reconnecting
+	connMu sync.Mutex
+
+	mu      sync.Mutex
+	entries map[string]string
+	// pending holds the ticket of the newest read in flight per key. An
+	// invalidation removes it, so a reply that raced with a change is not
+	// cached.
+	pending  map[string]uint64
+	ticket   uint64
+	data     *conn
+	listener *conn
+	closed   bool
+
+	hits, misses, invalidations, flushes atomic.Int64
+}
+
+// New connects a Cache to the server rdb points at and turns on tracking,
+// filling in defaults for unset options
+func New(ctx context.Context, rdb *redis.Client, opts Options) (*Cache, error) {
+	if opts.Protocol != 2 {
+		opts.Protocol = 3
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 10000
+	}
+	c := &Cache{
+		opt:     rdb.Options(),
+		opts:    opts,
+		entries: make(map[string]string),
+		pending: make(map[string]uint64),
+	}
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect opens the tracking connection, and in RESP2 mode the connection
+// invalidations are redirected to
+func (c *Cache) connect(ctx context.Context) error {
+	tracking := []interface{}{"CLIENT", "TRACKING", "ON"}
+
+	var listener *conn
+	if c.opts.Protocol == 2 {
+		var err error
+		listener, err = dial(ctx, c.opt, 2, c.opts.Hooks, c.onMessage)
+		if err != nil {
+			return err
+		}
+		id, err := listener.do(ctx, "CLIENT", "ID")
+		if err == nil {
+			// The confirmation is the reply, messages only come after it
+			_, err = listener.do(ctx, "SUBSCRIBE", invalidateChannel)
+		}
+		if err != nil {
+			listener.close()
+			return err
+		}
+		listener.subscribed.Store(true)
+		tracking = append(tracking, "REDIRECT", id)
+	}
+	if c.opts.Broadcast {
+		tracking = append(tracking, "BCAST")
+		for _, prefix := range c.opts.Prefixes {
+			tracking = append(tracking, "PREFIX", prefix)
+		}
+	}
+
+	data, err := dial(ctx, c.opt, c.opts.Protocol, c.opts.Hooks, c.onPush)
+	if err == nil {
+		if _, err = data.do(ctx, tracking...); err != nil {
+			data.close()
+		}
+	}
+	if err != nil {
+		if listener != nil {
+			listener.close()
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	c.data, c.listener = data, listener
+	c.mu.Unlock()
+	go c.watch(data, listener)
+	return nil
+}
+
+// watch drops the local cache when either connection fails, since
+// invalidations may have been lost with it. The next Get reconnects.
+func (c *Cache) watch(data, listener *conn) {
+	var lost <-chan struct{}
+	if listener != nil {
+		lost = listener.done
+	}
+	select {
+	case <-data.done:
+	case <-lost:
+	}
+
+	c.mu.Lock()
+	if c.data == data {
+		c.data, c.listener = nil, nil
+		c.flushLocked()
+	}
+	c.mu.Unlock()
+	data.close()
+	if listener != nil {
+		listener.close()
+	}
+}
+
+// Get returns the value of key, from local memory when possible. It
+// returns redis.Nil if the key does not exist.
+func (c *Cache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	if v, ok := c.entries[key]; ok {
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return v, nil
+	}
+	if c.closed {
+		c.mu.Unlock()
+		return "", ErrClosed
+	}
+	data := c.data
+	if data == nil {
+		c.mu.Unlock()
+		if err := c.reconnect(ctx); err != nil {
+			return "", err
+		}
+		return c.Get(ctx, key)
+	}
+	c.ticket++
+	ticket := c.ticket
+	cacheable := c.cacheable(key)
+	if cacheable {
+		c.pending[key] = ticket
+	}
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	reply, err := data.do(ctx, "GET", key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stillValid := c.pending[key] == ticket && c.data == data
+	if cacheable && c.pending[key] == ticket {
+		delete(c.pending, key)
+	}
+	if err != nil {
+		return "", err
+	}
+	v, ok := reply.(string)
+	if !ok {
+		return "", redis.Nil
+	}
+	if cacheable && stillValid {
+		if len(c.entries) >= c.opts.MaxEntries {
+			for k := range c.entries {
+				delete(c.entries, k)
+				break
+			}
+		}
+		c.entries[key] = v
+	}
+	return v, nil
+}
+
+// reconnect restores tracking after a connection failure
+func (c *Cache) reconnect(ctx context.Context) error {
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	c.mu.Lock()
+	connected, closed := c.data != nil, c.closed
+	c.mu.Unlock()
+	switch {
+	case closed:
+		return ErrClosed
+	case connected:
+		return nil
+	}
+	return c.connect(ctx)
+}
+
+// cacheable reports whether Redis will tell us about changes to key
+func (c *Cache) cacheable(key string) bool {
+	if !c.opts.Broadcast || len(c.opts.Prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range c.opts.Prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// onPush handles RESP3 pushes: ["invalidate", keys] or ["invalidate", nil]
+func (c *Cache) onPush(p push) {
+	if len(p) == 2 && p[0] == "invalidate" {
+		c.invalidate(p[1])
+	}
+}
+
+// onMessage handles RESP2 pub/sub messages: ["message", channel, keys]
+func (c *Cache) onMessage(p push) {
+	if len(p) == 3 && p[0] == "message" && p[1] == invalidateChannel {
+		c.invalidate(p[2])
+	}
+}
+
+// invalidate drops the given keys, or everything for a nil list, which
+// Redis sends after FLUSHALL and FLUSHDB
+func (c *Cache) invalidate(keys interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list, ok := keys.([]interface{})
+	if !ok {
+		c.flushLocked()
+		return
+	}
+	for _, key := range list {
+		k, _ := key.(string)
+		delete(c.entries, k)
+		delete(c.pending, k)
+		c.invalidations.Add(1)
+	}
+}
+
+func (c *Cache) flushLocked() {
+	clear(c.entries)
+	clear(c.pending)
+	c.flushes.Add(1)
+}
+
+// Len returns how many keys are held locally
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Stats returns a snapshot of the cache's counters
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Flushes:       c.flushes.Load(),
+	}
+}
+
+// Close drops the local cache and closes its connections
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	data, listener := c.data, c.listener
+	c.data, c.listener = nil, nil
+	clear(c.entries)
+	clear(c.pending)
+	c.mu.Unlock()
+	if data != nil {
+		data.close()
+	}
+	if listener != nil {
+		listener.close()
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package nearcache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// go-redis v9.12 cannot deliver RESP3 push messages, and a tracking
+// connection has to stay the same connection for as long as the local
+// cache is valid, so the cache talks to Redis over connections of its own
+// with this minimal RESP2/RESP3 implementation
+
+// replyError is an error reply from the server
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+
+// RedisError marks replyError as a server reply for go-redis hooks
+func (replyError) RedisError() {}
+
+// push is an out-of-band RESP3 push message such as an invalidation
+type push []interface{}
+
+// conn is one connection to Redis. Replies are matched to requests in
+// order, while pushes are handed to onPush from the reading goroutine.
+type conn struct {
+	nc     net.Conn
+	rd     *bufio.Reader
+	onPush func(push)
+	hooks  []redis.Hook
+	// subscribed makes every value a push, for a RESP2 connection in
+	// pub/sub mode
+	subscribed atomic.Bool
+
+	// mu is held from writing a request until its reply has been read
+	mu      sync.Mutex
+	replies chan interface{}
+	done    chan struct{}
+	err     error
+
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// dial connects with the address, credentials and database of opt, speaking
+// the given protocol version and passing every command through hooks
+func dial(ctx context.Context, opt *redis.Options, protocol int, hooks []redis.Hook, onPush func(push)) (*conn, error) {
+	d := net.Dialer{Timeout: opt.DialTimeout}
+	var nc net.Conn
+	var err error
+	if opt.TLSConfig != nil {
+		nc, err = (&tls.Dialer{NetDialer: &d, Config: opt.TLSConfig}).DialContext(ctx, "tcp", opt.Addr)
+	} else {
+		nc, err = d.DialContext(ctx, "tcp", opt.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{
+		nc:      nc,
+		rd:      bufio.NewReader(nc),
+		onPush:  onPush,
+		hooks:   hooks,
+		replies: make(chan interface{}),
+		done:    make(chan struct{}),
+		quit:    make(chan struct{}),
+	}
+	go c.readLoop()
+
+	var hello []interface{}
+	switch {
+	case protocol == 3:
+		hello = []interface{}{"HELLO", 3}
+		if opt.Password != "" {
+			hello = append(hello, "AUTH", username(opt), opt.Password)
+		}
+	case opt.Password != "":
+		hello = []interface{}{"AUTH", username(opt), opt.Password}
+	}
+	if hello != nil {
+		if _, err := c.do(ctx, hello...); err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+	if opt.DB != 0 {
+		if _, err := c.do(ctx, "SELECT", opt.DB); err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func username(opt *redis.Options) string {
+	if opt.Username == "" {
+		return "default"
+	}
+	return opt.Username
+}
+
+// do sends a command through the hooks and waits for its reply
+func (c *conn) do(ctx context.Context, args ...interface{}) (interface{}, error) {
+	if len(c.hooks) == 0 {
+		return c.roundTrip(ctx, args...)
+	}
+	var reply interface{}
+	process := func(ctx context.Context, cmd redis.Cmder) error {
+		var err error
+		reply, err = c.roundTrip(ctx, args...)
+		if err != nil {
+			cmd.SetErr(err)
+		} else {
+			cmd.(*redis.Cmd).SetVal(reply)
+		}
+		return err
+	}
+	for i := len(c.hooks) - 1; i >= 0; i-- {
+		process = c.hooks[i].ProcessHook(process)
+	}
+	err := process(ctx, redis.NewCmd(ctx, args...))
+	return reply, err
+}
+
+// roundTrip sends a command and waits for its reply. An error reply is
+// returned as a replyError. If ctx ends first the reply is still read and
+// discarded, so the next request gets its own.
+func (c *conn) roundTrip(ctx context.Context, args ...interface{}) (interface{}, error) {
+	c.mu.Lock()
+	if err := c.send(args...); err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	return c.wait(ctx)
+}
+
+// wait reads the reply to a request sent while holding mu, and releases mu
+func (c *conn) wait(ctx context.Context) (interface{}, error) {
+	select {
+	case reply := <-c.replies:
+		c.mu.Unlock()
+		if err, ok := reply.(replyError); ok {
+			return nil, err
+		}
+		return reply, nil
+	case <-c.done:
+		c.mu.Unlock()
+		return nil, c.err
+	case <-ctx.Done():
+		go func() {
+			select {
+			case <-c.replies:
+			case <-c.done:
+			}
+			c.mu.Unlock()
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// send writes a command as an array of bulk strings
+func (c *conn) send(args ...interface{}) error {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		s := fmt.Sprint(arg)
+		buf = append(buf, "$"+strconv.Itoa(len(s))+"\r\n"+s+"\r\n"...)
+	}
+	_, err := c.nc.Write(buf)
+	return err
+}
+
+// readLoop reads until the connection fails, then closes done
+func (c *conn) readLoop() {
+	for {
+		v, err := c.read()
+		if err != nil {
+			c.err = err
+			close(c.done)
+			return
+		}
+		if p, ok := v.(push); ok {
+			c.onPush(p)
+			continue
+		}
+		if items, ok := v.([]interface{}); ok && c.subscribed.Load() {
+			c.onPush(items)
+			continue
+		}
+		select {
+		case c.replies <- v:
+		case <-c.quit:
+			// Nobody is waiting for a reply after close
+		}
+	}
+}
+
+// close closes the connection and waits for readLoop to end
+func (c *conn) close() {
+	c.closeOnce.Do(func() {
+		close(c.quit)
+		c.nc.Close()
+	})
+	<-c.done
+}
+
+// read parses one value. Maps and sets become slices, nulls become nil and
+// attributes are skipped.
+func (c *conn) read() (interface{}, error) {
+	line, err := c.rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("nearcache: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+', ',', '(':
+		return body, nil
+	case '-', '!':
+		if kind == '!' {
+			s, err := c.bulk(body)
+			if err != nil {
+				return nil, err
+			}
+			body = s
+		}
+		return replyError(body), nil
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '#':
+		return body == "t", nil
+	case '_':
+		return nil, nil
+	case '$', '=':
+		if body == "-1" {
+			return nil, nil
+		}
+		s, err := c.bulk(body)
+		if kind == '=' && len(s) >= 4 {
+			s = s[4:] // drop the "txt:" style format prefix
+		}
+		return s, err
+	case '*', '~', '>', '%', '|':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("nearcache: malformed length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		if kind == '%' || kind == '|' {
+			n *= 2
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		switch kind {
+		case '>':
+			return push(items), nil
+		case '|':
+			return c.read() // the attributed value follows
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("nearcache: unknown reply type %q", kind)
+}
+
+// bulk reads a blob of the given length and its trailing CRLF
+func (c *conn) bulk(length string) (string, error) {
+	n, err := strconv.Atoi(length)
+	if err != nil || n < 0 {
+		return "", fmt.Errorf("nearcache: malformed length %q", length)
+	}
+	buf := make([]byte, n+2)
+	if _, err := io.ReadFull(c.rd, buf); err != nil {
+		return "", err
+	}
+	return string(buf[:n]), nil
+}
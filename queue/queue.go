@@ -0,0 +1,401 @@
+// Package queue is a reliable work queue on Redis lists. Workers take tasks
+// with BLMOVE, which atomically moves each task into a processing list of
+// the worker's own, and acknowledge it with LREM once it is done. A worker
+// that dies keeps its tasks in its processing list, so nothing is lost:
+// every worker refreshes a heartbeat key, and a reaper moves the tasks of
+// workers whose heartbeat expired back onto the queue for redelivery.
+//
+// Tasks are plain strings and are matched by value on acknowledgement, so
+// they should be unique, e.g. by carrying an ID.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNoTask is returned by Next when no task arrived within Options.Block
+var ErrNoTask = errors.New("queue: no task")
+
+// ErrLost is returned by Ack and Fail when the task is no longer in the
+// worker's processing list, because the reaper took it back after the
+// worker missed its heartbeats. The task has been or will be delivered
+// again, possibly to another worker.
+var ErrLost = errors.New("queue: task no longer held by this worker")
+
+// reapScript moves every task of a worker whose heartbeat expired back to
+// the consuming end of the queue, oldest first, and deregisters the worker.
+// It returns -1 if the worker turned out to be alive.
+var reapScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[4]) == 1 then
+  return -1
+end
+local n = 0
+while redis.call('LMOVE', KEYS[1], KEYS[2], 'LEFT', 'RIGHT') do
+  n = n + 1
+end
+redis.call('SREM', KEYS[3], ARGV[1])
+return n
+`)
+
+// failScript moves a task from a processing list to the failed list, only
+// if the processing list still holds it
+var failScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+  return 0
+end
+redis.call('LPUSH', KEYS[2], ARGV[1])
+return 1
+`)
+
+// Handler processes one task. Returning an error moves the task to the
+// failed list.
+type Handler func(ctx context.Context, task string) error
+
+// Options configures a Queue
+type Options struct {
+	// Prefix is prepended to the queue name to form keys, it defaults to
+	// "queue:"
+	Prefix string
+	// VisibilityTimeout is how long a worker may go without a heartbeat
+	// before its tasks are redelivered, it defaults to 30s
+	VisibilityTimeout time.Duration
+	// Heartbeat is how often workers refresh their heartbeat, it defaults to
+	// VisibilityTimeout/3
+	Heartbeat time.Duration
+	// ReapInterval is how often RunReaper looks for dead workers, it
+	// defaults to VisibilityTimeout/2
+	ReapInterval time.Duration
+	// Block bounds each BLMOVE call, and so how quickly Run notices
+	// shutdown. It defaults to 1s, which is also the shortest go-redis
+	// supports.
+	Block time.Duration
+}
+
+// Stats counts what a Queue and its workers have done so far
+type Stats struct {
+	Pushed    int64
+	Delivered int64
+	Acked     int64
+	Failed    int64
+	// Redelivered counts tasks the reaper took back from dead workers
+	Redelivered int64
+	// Reaped counts dead workers whose tasks were taken back
+	Reaped int64
+}
+
+// Depth is how many tasks are where right now
+type Depth struct {
+	Pending int64
+	// Processing holds the number of tasks per registered worker
+	Processing map[string]int64
+	Failed     int64
+}
+
+// Queue is a named queue and the keys that belong to it
+type Queue struct {
+	rdb  *redis.Client
+	name string
+	opts Options
+
+	pushed, delivered, acked, failed, redelivered, reaped atomic.Int64
+}
+
+// New returns a Queue for name, filling in defaults for unset options
+func New(rdb *redis.Client, name string, opts Options) *Queue {
+	if opts.Prefix == "" {
+		opts.Prefix = "queue:"
+	}
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = 30 * time.Second
+	}
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = opts.VisibilityTimeout / 3
+	}
+	if opts.ReapInterval <= 0 {
+		opts.ReapInterval = opts.VisibilityTimeout / 2
+	}
+	if opts.Block <= 0 {
+		opts.Block = time.Second
+	}
+	return &Queue{rdb: rdb, name: name, opts: opts}
+}
+
+// Stats returns a snapshot of the queue's counters
+func (q *Queue) Stats() Stats {
+	return Stats{
+		Pushed:      q.pushed.Load(),
+		Delivered:   q.delivered.Load(),
+		Acked:       q.acked.Load(),
+		Failed:      q.failed.Load(),
+		Redelivered: q.redelivered.Load(),
+		Reaped:      q.reaped.Load(),
+	}
+}
+
+// Push appends tasks to the queue, to be delivered in order
+func (q *Queue) Push(ctx context.Context, tasks ...string) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(tasks))
+	for i, task := range tasks {
+		args[i] = task
+	}
+	// Workers take from the right, so LPUSH makes the list first in, first out
+	if err := q.rdb.LPush(ctx, q.pendingKey(), args...).Err(); err != nil {
+		return err
+	}
+	q.pushed.Add(int64(len(tasks)))
+	return nil
+}
+
+// Depth reports the length of the queue, of each worker's processing list
+// and of the failed list
+func (q *Queue) Depth(ctx context.Context) (Depth, error) {
+	workers, err := q.rdb.SMembers(ctx, q.workersKey()).Result()
+	if err != nil {
+		return Depth{}, err
+	}
+	var pending, failed *redis.IntCmd
+	processing := make(map[string]*redis.IntCmd, len(workers))
+	_, err = q.rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pending = pipe.LLen(ctx, q.pendingKey())
+		failed = pipe.LLen(ctx, q.failedKey())
+		for _, id := range workers {
+			processing[id] = pipe.LLen(ctx, q.processingKey(id))
+		}
+		return nil
+	})
+	if err != nil {
+		return Depth{}, err
+	}
+	d := Depth{Pending: pending.Val(), Failed: failed.Val(), Processing: make(map[string]int64, len(workers))}
+	for id, cmd := range processing {
+		d.Processing[id] = cmd.Val()
+	}
+	return d, nil
+}
+
+// Failed returns the tasks whose handler returned an error, newest first
+func (q *Queue) Failed(ctx context.Context) ([]string, error) {
+	return q.rdb.LRange(ctx, q.failedKey(), 0, -1).Result()
+}
+
+// Reap redelivers the tasks of every registered worker whose heartbeat has
+// expired, returning how many tasks went back on the queue
+func (q *Queue) Reap(ctx context.Context) (int, error) {
+	workers, err := q.rdb.SMembers(ctx, q.workersKey()).Result()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, id := range workers {
+		keys := []string{q.processingKey(id), q.pendingKey(), q.workersKey(), q.heartbeatKey(id)}
+		n, err := reapScript.Run(ctx, q.rdb, keys, id).Int()
+		if err != nil {
+			return total, fmt.Errorf("queue: reaping %s: %w", id, err)
+		}
+		if n < 0 {
+			continue
+		}
+		q.reaped.Add(1)
+		q.redelivered.Add(int64(n))
+		total += n
+	}
+	return total, nil
+}
+
+// RunReaper calls Reap every ReapInterval until ctx is cancelled. onReap,
+// if set, is told about every run that redelivered tasks.
+func (q *Queue) RunReaper(ctx context.Context, onReap func(tasks int)) error {
+	ticker := time.NewTicker(q.opts.ReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		n, err := q.Reap(ctx)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if n > 0 && onReap != nil {
+			onReap(n)
+		}
+	}
+}
+
+// Delete removes the queue and every list belonging to it
+func (q *Queue) Delete(ctx context.Context) error {
+	workers, err := q.rdb.SMembers(ctx, q.workersKey()).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{q.pendingKey(), q.failedKey(), q.workersKey()}
+	for _, id := range workers {
+		keys = append(keys, q.processingKey(id), q.heartbeatKey(id))
+	}
+	return q.rdb.Del(ctx, keys...).Err()
+}
+
+// Worker returns a worker with the given ID, which must be unique among the
+// queue's live workers
+func (q *Queue) Worker(id string) *Worker {
+	return &Worker{q: q, id: id}
+}
+
+func (q *Queue) pendingKey() string { return q.opts.Prefix + q.name }
+
+func (q *Queue) failedKey() string { return q.opts.Prefix + q.name + ":failed" }
+
+func (q *Queue) workersKey() string { return q.opts.Prefix + q.name + ":workers" }
+
+func (q *Queue) processingKey(id string) string {
+	return q.opts.Prefix + q.name + ":processing:" + id
+}
+
+func (q *Queue) heartbeatKey(id string) string {
+	return q.opts.Prefix + q.name + ":heartbeat:" + id
+}
+
+// Worker takes tasks from a Queue into its own processing list
+type Worker struct {
+	q  *Queue
+	id string
+}
+
+// ID returns the worker's ID
+func (w *Worker) ID() string { return w.id }
+
+// Register announces the worker to the reaper with a first heartbeat
+func (w *Worker) Register(ctx context.Context) error {
+	return w.Heartbeat(ctx)
+}
+
+// Heartbeat tells the reaper the worker is still alive. It also registers
+// the worker again, in case it stalled long enough to be reaped, so the
+// reaper keeps watching the tasks it takes from now on.
+func (w *Worker) Heartbeat(ctx context.Context) error {
+	_, err := w.q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, w.q.workersKey(), w.id)
+		pipe.Set(ctx, w.q.heartbeatKey(w.id), time.Now().UnixMilli(), w.q.opts.VisibilityTimeout)
+		return nil
+	})
+	return err
+}
+
+// Next waits up to Options.Block for a task and moves it into the worker's
+// processing list, returning ErrNoTask if none arrived
+func (w *Worker) Next(ctx context.Context) (string, error) {
+	task, err := w.q.rdb.BLMove(ctx, w.q.pendingKey(), w.q.processingKey(w.id), "RIGHT", "LEFT", w.q.opts.Block).Result()
+	if err == redis.Nil {
+		return "", ErrNoTask
+	}
+	if err != nil {
+		return "", err
+	}
+	w.q.delivered.Add(1)
+	return task, nil
+}
+
+// Ack removes a finished task from the processing list. It returns ErrLost
+// if the task was reaped in the meantime.
+func (w *Worker) Ack(ctx context.Context, task string) error {
+	removed, err := w.q.rdb.LRem(ctx, w.q.processingKey(w.id), 1, task).Result()
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return ErrLost
+	}
+	w.q.acked.Add(1)
+	return nil
+}
+
+// Fail moves a task from the processing list to the failed list. It
+// returns ErrLost if the task was reaped in the meantime.
+func (w *Worker) Fail(ctx context.Context, task string) error {
+	keys := []string{w.q.processingKey(w.id), w.q.failedKey()}
+	moved, err := failScript.Run(ctx, w.q.rdb, keys, task).Int()
+	if err != nil {
+		return err
+	}
+	if moved == 0 {
+		return ErrLost
+	}
+	w.q.failed.Add(1)
+	return nil
+}
+
+// Run registers the worker and handles tasks one at a time, with a
+// heartbeat in the background, until ctx is cancelled. A task whose handler
+// is still running at that point stays in the processing list, as if the
+// process had died, and is redelivered by the reaper. Use Stop for a clean
+// shutdown.
+func (w *Worker) Run(ctx context.Context, handler Handler) error {
+	if err := w.Register(ctx); err != nil {
+		return err
+	}
+
+	var heartbeat sync.WaitGroup
+	heartbeat.Add(1)
+	go func() {
+		defer heartbeat.Done()
+		ticker := time.NewTicker(w.q.opts.Heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.Heartbeat(ctx)
+			}
+		}
+	}()
+	defer heartbeat.Wait()
+
+	for ctx.Err() == nil {
+		task, err := w.Next(ctx)
+		if err == ErrNoTask || ctx.Err() != nil {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("queue: BLMOVE: %w", err)
+		}
+
+		err = handler(ctx, task)
+		if ctx.Err() != nil {
+			// Killed mid-task, leave it for the reaper
+			return nil
+		}
+		if err != nil {
+			err = w.Fail(ctx, task)
+		} else {
+			err = w.Ack(ctx, task)
+		}
+		// A lost task is someone else's now, carry on with the next one
+		if err != nil && err != ErrLost {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop returns the worker's unfinished tasks to the queue and deregisters
+// it. Call it after Run has returned.
+func (w *Worker) Stop(ctx context.Context) error {
+	_, err := w.q.rdb.Del(ctx, w.q.heartbeatKey(w.id)).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{w.q.processingKey(w.id), w.q.pendingKey(), w.q.workersKey(), w.q.heartbeatKey(w.id)}
+	return reapScript.Run(ctx, w.q.rdb, keys, w.id).Err()
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// KeyFunc picks the rate-limit key for a request
+type KeyFunc func(r *http.Request) string
+
+// ClientIP keys requests by the remote address without its port
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware charges one unit per request against l under the key from
+// keyFunc. Rejected requests get 429 Too Many Requests with a Retry-After
+// header. If Redis cannot be reached the request is let through, so an
+// outage of the limiter does not take the service down with it.
+func Middleware(l Limiter, keyFunc KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := l.Allow(r.Context(), keyFunc(r), 1)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			if !res.Allowed {
+				if res.RetryAfter > 0 {
+					seconds := int(math.Ceil(res.RetryAfter.Seconds()))
+					w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				}
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+// Package ratelimit provides Redis-backed rate limiters behind one Limiter
+// interface: a fixed window on INCR and EXPIRE, a sliding log on sorted sets,
+// a token bucket and GCRA, the last three as Lua scripts so each decision is
+// atomic. Time comes from the caller's clock, so clients sharing a limit
+// should keep their clocks in sync.
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrInvalidUnits is returned by Allow when n is not positive
+var ErrInvalidUnits = errors.New("ratelimit: n must be positive")
+
+// Result is the outcome of one Allow call
+type Result struct {
+	Allowed bool
+	// Remaining is how many more units would be allowed right now
+	Remaining int
+	// RetryAfter is how long to wait before the same request could be
+	// allowed, zero when it was
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether n units for key may proceed now. n must be at
+// least 1.
+type Limiter interface {
+	Allow(ctx context.Context, key string, n int) (Result, error)
+}
+
+// nowMillis returns t as fractional Unix milliseconds for the scripts
+func nowMillis(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMicro())/1000, 'f', 3, 64)
+}
+
+// scriptResult turns an {allowed, remaining, retry ms} script reply into a Result
+func scriptResult(cmd *redis.Cmd) (Result, error) {
+	vals, err := cmd.Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Allowed:    vals[0] == 1,
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+	}, nil
+}
+
+// FixedWindow counts units per key in consecutive windows of a fixed length.
+// It is the cheapest limiter but lets up to twice the limit through around
+// a window boundary, and rejected units still count against the window.
+type FixedWindow struct {
+	rdb    *redis.Client
+	Limit  int
+	Window time.Duration
+	Prefix string
+	Now    func() time.Time
+}
+
+// NewFixedWindow allows limit units per window
+func NewFixedWindow(rdb *redis.Client, limit int, window time.Duration) *FixedWindow {
+	mustBePositive("limit", int64(limit))
+	mustBePositive("window", window.Milliseconds())
+	return &FixedWindow{rdb: rdb, Limit: limit, Window: window, Prefix: "ratelimit:fixed:", Now: time.Now}
+}
+
+// Allow implements Limiter
+func (l *FixedWindow) Allow(ctx context.Context, key string, n int) (Result, error) {
+	if n <= 0 {
+		return Result{}, ErrInvalidUnits
+	}
+	now := l.Now().UnixMilli()
+	window := l.Window.Milliseconds()
+	index := now / window
+	windowKey := l.Prefix + key + ":" + strconv.FormatInt(index, 10)
+
+	var count *redis.IntCmd
+	_, err := l.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.IncrBy(ctx, windowKey, int64(n))
+		pipe.PExpire(ctx, windowKey, l.Window)
+		return nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	if used := int(count.Val()); used <= l.Limit {
+		return Result{Allowed: true, Remaining: l.Limit - used}, nil
+	}
+	retry := (index+1)*window - now
+	return Result{RetryAfter: time.Duration(retry) * time.Millisecond}, nil
+}
+
+// slidingLogScript trims entries older than the window and adds n entries
+// if they fit. Otherwise it works out when enough old entries will expire.
+var slidingLogScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count + n <= limit then
+  for i = 1, n do
+    redis.call('ZADD', KEYS[1], now, ARGV[5] .. i)
+  end
+  redis.call('PEXPIRE', KEYS[1], math.ceil(window))
+  return {1, limit - count - n, 0}
+end
+
+local retry = window
+if n <= limit then
+  local oldest = redis.call('ZRANGE', KEYS[1], count + n - limit - 1, count + n - limit - 1, 'WITHSCORES')
+  retry = tonumber(oldest[2]) + window - now
+end
+return {0, math.max(limit - count, 0), math.ceil(retry)}
+`)
+
+// SlidingLog keeps a timestamp per allowed unit in a sorted set, giving an
+// exact limit over any window-length interval at the cost of memory
+// proportional to the limit
+type SlidingLog struct {
+	rdb    *redis.Client
+	Limit  int
+	Window time.Duration
+	Prefix string
+	Now    func() time.Time
+}
+
+// NewSlidingLog allows limit units in any interval of length window
+func NewSlidingLog(rdb *redis.Client, limit int, window time.Duration) *SlidingLog {
+	mustBePositive("limit", int64(limit))
+	mustBePositive("window", window.Milliseconds())
+	return &SlidingLog{rdb: rdb, Limit: limit, Window: window, Prefix: "ratelimit:log:", Now: time.Now}
+}
+
+// Allow implements Limiter
+func (l *SlidingLog) Allow(ctx context.Context, key string, n int) (Result, error) {
+	if n <= 0 {
+		return Result{}, ErrInvalidUnits
+	}
+	// Entries need unique members even when they share a timestamp
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Result{}, err
+	}
+	return scriptResult(slidingLogScript.Run(ctx, l.rdb, []string{l.Prefix + key},
+		nowMillis(l.Now()), l.Window.Milliseconds(), l.Limit, n, hex.EncodeToString(id)+":"))
+}
+
+// tokenBucketScript refills the bucket for the time since the last call,
+// then takes n tokens if there are enough
+var tokenBucketScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed, retry = 0, 0
+if tokens >= n then
+  tokens = tokens - n
+  allowed = 1
+elseif n <= burst then
+  retry = math.ceil((n - tokens) / rate)
+else
+  retry = -1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.max(1, math.ceil(burst / rate)))
+return {allowed, math.floor(tokens), retry}
+`)
+
+// TokenBucket holds up to Burst tokens, refilled at Rate per second. Each
+// unit takes a token, so short bursts pass while the long-run rate is capped.
+type TokenBucket struct {
+	rdb    *redis.Client
+	Rate   float64
+	Burst  int
+	Prefix string
+	Now    func() time.Time
+}
+
+// NewTokenBucket refills rate tokens per second into a bucket of burst
+func NewTokenBucket(rdb *redis.Client, rate float64, burst int) *TokenBucket {
+	if !(rate > 0) {
+		panic("ratelimit: rate must be positive")
+	}
+	mustBePositive("burst", int64(burst))
+	return &TokenBucket{rdb: rdb, Rate: rate, Burst: burst, Prefix: "ratelimit:bucket:", Now: time.Now}
+}
+
+// Allow implements Limiter. A request larger than Burst can never pass and
+// gets a negative RetryAfter.
+func (l *TokenBucket) Allow(ctx context.Context, key string, n int) (Result, error) {
+	if n <= 0 {
+		return Result{}, ErrInvalidUnits
+	}
+	return scriptResult(tokenBucketScript.Run(ctx, l.rdb, []string{l.Prefix + key},
+		nowMillis(l.Now()), l.Rate/1000, l.Burst, n))
+}
+
+// gcraScript keeps only the theoretical arrival time (TAT) of the next
+// request. A request is allowed if it would not push the TAT more than the
+// burst tolerance ahead of now.
+var gcraScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local n = tonumber(ARGV[4])
+
+local tat = math.max(tonumber(redis.call('GET', KEYS[1])) or now, now)
+local tolerance = interval * burst
+local ahead = tat + n * interval - now
+if ahead > tolerance then
+  return {0, math.floor((tolerance - (tat - now)) / interval), math.ceil(ahead - tolerance)}
+end
+
+redis.call('SET', KEYS[1], tostring(now + ahead), 'PX', math.max(1, math.ceil(ahead)))
+return {1, math.floor((tolerance - ahead) / interval), 0}
+`)
+
+// GCRA is the generic cell rate algorithm. It behaves like a token bucket
+// with the same limit and burst but stores a single timestamp per key.
+type GCRA struct {
+	rdb    *redis.Client
+	Limit  int
+	Period time.Duration
+	Burst  int
+	Prefix string
+	Now    func() time.Time
+}
+
+// NewGCRA allows limit units per period, evenly spaced, with bursts of up
+// to burst units
+func NewGCRA(rdb *redis.Client, limit int, period time.Duration, burst int) *GCRA {
+	mustBePositive("limit", int64(limit))
+	mustBePositive("period", period.Microseconds())
+	mustBePositive("burst", int64(burst))
+	return &GCRA{rdb: rdb, Limit: limit, Period: period, Burst: burst, Prefix: "ratelimit:gcra:", Now: time.Now}
+}
+
+// Allow implements Limiter
+func (l *GCRA) Allow(ctx context.Context, key string, n int) (Result, error) {
+	if n <= 0 {
+		return Result{}, ErrInvalidUnits
+	}
+	interval := float64(l.Period.Microseconds()) / 1000 / float64(l.Limit)
+	return scriptResult(gcraScript.Run(ctx, l.rdb, []string{l.Prefix + key},
+		nowMillis(l.Now()), interval, l.Burst, n))
+}
+
+// mustBePositive panics on a limiter setting that would divide by zero,
+// never expire or reject every request, as time.NewTicker does for a bad
+// interval
+func mustBePositive(name string, v int64) {
+	if v <= 0 {
+		panic("ratelimit: " + name + " must be positive")
+	}
+}
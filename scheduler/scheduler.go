@@ -0,0 +1,378 @@
+// Package scheduler runs delayed and recurring jobs from a Redis sorted set
+// scored by due time. Workers claim due jobs with a Lua script that moves
+// them to a claimed set in the same step, so no two workers ever get the
+// same run of a job. A claim is a lease: a job whose worker died without
+// completing it becomes due again once the lease runs out, and each claim
+// carries a token so a worker that overran its lease cannot end someone
+// else's. Time comes from the callers' clocks, so workers should keep them
+// in sync.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound is returned for job IDs that are not scheduled
+var ErrNotFound = errors.New("scheduler: job not found")
+
+// claimScript first returns expired claims to the due set at their
+// original due time, then moves up to ARGV[3] due jobs to the claimed set
+// with a lease, returning each job's body and due time. Each claim is
+// recorded in the claims hash as "<token>:<due>" with the token ARGV[4].
+var claimScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', now)) do
+  redis.call('ZREM', KEYS[2], id)
+  local claim = redis.call('HGET', KEYS[4], id)
+  redis.call('HDEL', KEYS[4], id)
+  local due = claim and string.match(claim, ':(.*)$')
+  redis.call('ZADD', KEYS[1], due or now, id)
+end
+local claimed = {}
+local due = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'WITHSCORES', 'LIMIT', 0, ARGV[3])
+for i = 1, #due, 2 do
+  local id = due[i]
+  redis.call('ZREM', KEYS[1], id)
+  local body = redis.call('HGET', KEYS[3], id)
+  if body then
+    redis.call('ZADD', KEYS[2], now + tonumber(ARGV[2]), id)
+    redis.call('HSET', KEYS[4], id, ARGV[4] .. ':' .. due[i + 1])
+    table.insert(claimed, body)
+    table.insert(claimed, due[i + 1])
+  end
+end
+return claimed
+`)
+
+// completeScript ends the claim with token ARGV[2], scheduling the next run
+// at ARGV[3] if it is set and deleting the job otherwise. It returns 0 if
+// the claim was gone, because the job was cancelled or the lease ran out
+// and someone else may have claimed it since.
+var completeScript = redis.NewScript(`
+local claim = redis.call('HGET', KEYS[4], ARGV[1])
+if not claim or string.sub(claim, 1, #ARGV[2] + 1) ~= ARGV[2] .. ':' then
+  return 0
+end
+redis.call('HDEL', KEYS[4], ARGV[1])
+redis.call('ZREM', KEYS[2], ARGV[1])
+if ARGV[3] ~= '' then
+  redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+else
+  redis.call('HDEL', KEYS[3], ARGV[1])
+end
+return 1
+`)
+
+// Job is a unit of scheduled work
+type Job struct {
+	// ID identifies the job for Cancel and Reschedule, Schedule generates
+	// one if it is empty
+	ID      string `json:"id"`
+	Payload string `json:"payload"`
+	// Every makes the job recurring, running again Every after each due time
+	Every time.Duration `json:"every,omitempty"`
+
+	// Due is when this run of the job was due, set on claimed jobs
+	Due time.Time `json:"-"`
+	// claim is the token of the claim that returned the job
+	claim string
+}
+
+// Handler runs one job. Returning an error retries it after
+// Options.RetryDelay.
+type Handler func(ctx context.Context, job Job) error
+
+// Options configures a Scheduler
+type Options struct {
+	// Prefix is prepended to the scheduler name to form keys, it defaults
+	// to "scheduler:"
+	Prefix string
+	// Lease is how long a claimed job is reserved for its worker, it
+	// defaults to 30s. A handler that runs longer may see its job claimed
+	// again by someone else, and its Complete or Fail then returns
+	// ErrNotFound.
+	Lease time.Duration
+	// PollInterval is how often Run looks for due jobs when it found none,
+	// it defaults to 100ms
+	PollInterval time.Duration
+	// Batch is how many jobs one claim takes at most, it defaults to 10
+	Batch int
+	// RetryDelay is how long a failed job waits before it is due again, it
+	// defaults to 1s
+	RetryDelay time.Duration
+}
+
+// Stats counts what a Scheduler has done so far
+type Stats struct {
+	Scheduled   int64
+	Claimed     int64
+	Completed   int64
+	Failed      int64
+	Cancelled   int64
+	Rescheduled int64
+}
+
+// Scheduler is a named set of scheduled jobs
+type Scheduler struct {
+	rdb  *redis.Client
+	name string
+	opts Options
+
+	scheduled, claimed, completed, failed, cancelled, rescheduled atomic.Int64
+}
+
+// New returns a Scheduler for name, filling in defaults for unset options
+func New(rdb *redis.Client, name string, opts Options) *Scheduler {
+	if opts.Prefix == "" {
+		opts.Prefix = "scheduler:"
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 30 * time.Second
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 100 * time.Millisecond
+	}
+	if opts.Batch <= 0 {
+		opts.Batch = 10
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = time.Second
+	}
+	return &Scheduler{rdb: rdb, name: name, opts: opts}
+}
+
+// Stats returns a snapshot of the scheduler's counters
+func (s *Scheduler) Stats() Stats {
+	return Stats{
+		Scheduled:   s.scheduled.Load(),
+		Claimed:     s.claimed.Load(),
+		Completed:   s.completed.Load(),
+		Failed:      s.failed.Load(),
+		Cancelled:   s.cancelled.Load(),
+		Rescheduled: s.rescheduled.Load(),
+	}
+}
+
+// Schedule stores job to run at the given time and returns its ID. An
+// existing job with the same ID is replaced.
+func (s *Scheduler) Schedule(ctx context.Context, job Job, at time.Time) (string, error) {
+	if job.ID == "" {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		job.ID = hex.EncodeToString(buf)
+	}
+	body, err := json.Marshal(job)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, s.jobsKey(), job.ID, body)
+		pipe.ZRem(ctx, s.claimedKey(), job.ID)
+		pipe.HDel(ctx, s.claimsKey(), job.ID)
+		pipe.ZAdd(ctx, s.dueKey(), redis.Z{Score: millis(at), Member: job.ID})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	s.scheduled.Add(1)
+	return job.ID, nil
+}
+
+// Cancel removes a job, including future runs of a recurring one. A run
+// already in progress finishes, but is not scheduled again.
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	var removed *redis.IntCmd
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, s.dueKey(), id)
+		pipe.ZRem(ctx, s.claimedKey(), id)
+		pipe.HDel(ctx, s.claimsKey(), id)
+		removed = pipe.HDel(ctx, s.jobsKey(), id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if removed.Val() == 0 {
+		return ErrNotFound
+	}
+	s.cancelled.Add(1)
+	return nil
+}
+
+// Reschedule moves a job that is waiting to run to a new time
+func (s *Scheduler) Reschedule(ctx context.Context, id string, at time.Time) error {
+	changed, err := s.rdb.ZAddArgs(ctx, s.dueKey(), redis.ZAddArgs{
+		XX:      true,
+		Ch:      true,
+		Members: []redis.Z{{Score: millis(at), Member: id}},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	if changed == 0 {
+		// Either not waiting, or already due at exactly that time
+		if _, err := s.rdb.ZScore(ctx, s.dueKey(), id).Result(); err == redis.Nil {
+			return ErrNotFound
+		}
+	}
+	s.rescheduled.Add(1)
+	return nil
+}
+
+// Pending returns the waiting jobs' IDs and due times, soonest first
+func (s *Scheduler) Pending(ctx context.Context) ([]string, []time.Time, error) {
+	entries, err := s.rdb.ZRangeWithScores(ctx, s.dueKey(), 0, -1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := make([]string, len(entries))
+	due := make([]time.Time, len(entries))
+	for i, z := range entries {
+		ids[i] = z.Member.(string)
+		due[i] = time.UnixMilli(int64(z.Score))
+	}
+	return ids, due, nil
+}
+
+// Claim takes up to Options.Batch jobs that are due, leasing them to the
+// caller until Complete or Fail
+func (s *Scheduler) Claim(ctx context.Context) ([]Job, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+
+	now := time.Now()
+	res, err := claimScript.Run(ctx, s.rdb, s.keys(), millis(now), s.opts.Lease.Milliseconds(), s.opts.Batch, token).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	jobs := make([]Job, 0, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		var job Job
+		if err := json.Unmarshal([]byte(res[i]), &job); err != nil {
+			return jobs, fmt.Errorf("scheduler: decoding job: %w", err)
+		}
+		due, _ := strconv.ParseFloat(res[i+1], 64)
+		job.Due = time.UnixMilli(int64(due))
+		job.claim = token
+		jobs = append(jobs, job)
+	}
+	s.claimed.Add(int64(len(jobs)))
+	return jobs, nil
+}
+
+// Complete ends a claimed run, scheduling the next one for recurring jobs.
+// Runs missed while nobody was working are skipped rather than caught up.
+func (s *Scheduler) Complete(ctx context.Context, job Job) error {
+	next := ""
+	if job.Every > 0 {
+		at := job.Due.Add(job.Every)
+		if now := time.Now(); at.Before(now) {
+			missed := now.Sub(at)/job.Every + 1
+			at = at.Add(missed * job.Every)
+		}
+		next = strconv.FormatFloat(millis(at), 'f', -1, 64)
+	}
+	if err := s.finish(ctx, job, next); err != nil {
+		return err
+	}
+	s.completed.Add(1)
+	return nil
+}
+
+// Fail ends a claimed run, making the job due again after RetryDelay
+func (s *Scheduler) Fail(ctx context.Context, job Job) error {
+	retry := strconv.FormatFloat(millis(time.Now().Add(s.opts.RetryDelay)), 'f', -1, 64)
+	if err := s.finish(ctx, job, retry); err != nil {
+		return err
+	}
+	s.failed.Add(1)
+	return nil
+}
+
+// finish ends job's claim if it still holds it, scheduling the job again
+// at next unless next is empty
+func (s *Scheduler) finish(ctx context.Context, job Job, next string) error {
+	if job.claim == "" {
+		return ErrNotFound
+	}
+	held, err := completeScript.Run(ctx, s.rdb, s.keys(), job.ID, job.claim, next).Int()
+	if err != nil {
+		return err
+	}
+	if held == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Run claims and handles due jobs until ctx is cancelled, polling every
+// PollInterval while there are none
+func (s *Scheduler) Run(ctx context.Context, handler Handler) error {
+	for {
+		jobs, err := s.Claim(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("scheduler: claiming: %w", err)
+		}
+		for _, job := range jobs {
+			// Finish the batch even during shutdown, the jobs are ours
+			if handler(ctx, job) != nil {
+				err = s.Fail(context.WithoutCancel(ctx), job)
+			} else {
+				err = s.Complete(context.WithoutCancel(ctx), job)
+			}
+			if err != nil && err != ErrNotFound {
+				return err
+			}
+		}
+		if len(jobs) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.opts.PollInterval):
+		}
+	}
+}
+
+// Delete removes every job of the scheduler
+func (s *Scheduler) Delete(ctx context.Context) error {
+	return s.rdb.Del(ctx, s.keys()...).Err()
+}
+
+// keys returns the keys the scripts work on, in the order they expect
+func (s *Scheduler) keys() []string {
+	return []string{s.dueKey(), s.claimedKey(), s.jobsKey(), s.claimsKey()}
+}
+
+func (s *Scheduler) dueKey() string { return s.opts.Prefix + s.name + ":due" }
+
+func (s *Scheduler) claimedKey() string { return s.opts.Prefix + s.name + ":claimed" }
+
+func (s *Scheduler) jobsKey() string { return s.opts.Prefix + s.name + ":jobs" }
+
+func (s *Scheduler) claimsKey() string { return s.opts.Prefix + s.name + ":claims" }
+
+// millis returns t as Unix milliseconds for sorted set scores
+func millis(t time.Time) float64 {
+	return float64(t.UnixMilli())
+}
@@ -0,0 +1,6 @@
+-- Compare-and-delete: delete KEYS[1] only if it currently holds ARGV[1]
+-- Returns 1 when the key was deleted, 0 otherwise
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  return redis.call('DEL', KEYS[1])
+end
+return 0
@@ -0,0 +1,7 @@
+-- Compare-and-set: set KEYS[1] to ARGV[2] only if it currently holds ARGV[1]
+-- Returns 1 when the value was swapped, 0 otherwise
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+  redis.call('SET', KEYS[1], ARGV[2])
+  return 1
+end
+return 0
@@ -0,0 +1,12 @@
+-- Compare-and-set: set KEYS[1] to ARGV[2] only if it currently holds ARGV[1]
+-- v2: an optional ARGV[3] gives the new value a TTL in milliseconds
+-- Returns 1 when the value was swapped, 0 otherwise
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+  return 0
+end
+if ARGV[3] then
+  redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+  redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
@@ -0,0 +1,245 @@
+// Package scripting keeps a registry of versioned Lua scripts. Scripts are
+// loaded from files named <name>.v<version>.lua, the highest version of each
+// name wins, and they are called by name through EVALSHA with a transparent
+// reload when the server's script cache has been flushed.
+package scripting
+
+import (
+	"context"
+	"crypto/sha1"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Builtin holds the scripts shipped with the playground, under "lua"
+//
+//go:embed lua/*.lua
+var Builtin embed.FS
+
+// Script is one version of a named Lua script
+type Script struct {
+	Name    string
+	Version int
+	// File is the file the script was loaded from, empty for scripts
+	// registered in code
+	File   string
+	Source string
+	SHA    string
+}
+
+// label names the script in errors, by its file when it has one
+func (s *Script) label() string {
+	if s.File != "" {
+		return s.File
+	}
+	return fmt.Sprintf("%s v%d", s.Name, s.Version)
+}
+
+// ScriptError is a script failure with the line it happened on, when the
+// server reported one
+type ScriptError struct {
+	Script *Script
+	Line   int
+	Err    error
+}
+
+func (e *ScriptError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("scripting: %s line %d: %v", e.Script.label(), e.Line, e.Err)
+	}
+	return fmt.Sprintf("scripting: %s: %v", e.Script.label(), e.Err)
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// Registry maps script names to their latest version
+type Registry struct {
+	mu      sync.RWMutex
+	scripts map[string]*Script
+	reloads atomic.Int64
+}
+
+// New returns an empty registry
+func New() *Registry {
+	return &Registry{scripts: make(map[string]*Script)}
+}
+
+// LoadDir returns a registry of the .lua files in dir
+func LoadDir(dir string) (*Registry, error) {
+	return LoadFS(os.DirFS(dir), ".")
+}
+
+// LoadFS returns a registry of the .lua files in dir of fsys
+func LoadFS(fsys fs.FS, dir string) (*Registry, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.lua"))
+	if err != nil {
+		return nil, err
+	}
+
+	r := New()
+	for _, file := range files {
+		source, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		name, version, err := parseFileName(path.Base(file))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := r.Register(name, version, path.Base(file), string(source)); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// versionRe matches a ".v<digits>" version suffix
+var versionRe = regexp.MustCompile(`^(.+)\.v(\d+)$`)
+
+// parseFileName splits "cas.v2.lua" into "cas" and 2. A file without a
+// version suffix, such as "my.validate.lua", is version 1.
+func parseFileName(file string) (string, int, error) {
+	name := strings.TrimSuffix(file, ".lua")
+	m := versionRe.FindStringSubmatch(name)
+	if m == nil {
+		return name, 1, nil
+	}
+	version, err := strconv.Atoi(m[2])
+	if err != nil || version < 1 {
+		return "", 0, fmt.Errorf("scripting: %s: version must be a positive number", file)
+	}
+	return m[1], version, nil
+}
+
+// Register adds a script version, with the file it came from if any. Only
+// the highest version of a name is kept, registering the same version twice
+// with different source is an error.
+func (r *Registry) Register(name string, version int, file, source string) (*Script, error) {
+	sum := sha1.Sum([]byte(source))
+	s := &Script{
+		Name:    name,
+		Version: version,
+		File:    file,
+		Source:  source,
+		SHA:     hex.EncodeToString(sum[:]),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, ok := r.scripts[name]
+	switch {
+	case !ok || current.Version < version:
+		r.scripts[name] = s
+	case current.Version == version && current.SHA != s.SHA:
+		return nil, fmt.Errorf("scripting: %s registered twice with different source", s.label())
+	}
+	return r.scripts[name], nil
+}
+
+// Get returns the latest version of the named script
+func (r *Registry) Get(name string) (*Script, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.scripts[name]
+	return s, ok
+}
+
+// Scripts returns the registered scripts sorted by name
+func (r *Registry) Scripts() []*Script {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scripts := make([]*Script, 0, len(r.scripts))
+	for _, s := range r.scripts {
+		scripts = append(scripts, s)
+	}
+	sort.Slice(scripts, func(i, j int) bool { return scripts[i].Name < scripts[j].Name })
+	return scripts
+}
+
+// Reloads counts how often a script had to be loaded again after NOSCRIPT
+func (r *Registry) Reloads() int64 {
+	return r.reloads.Load()
+}
+
+// Preload sends every script to the server with SCRIPT LOAD, so that syntax
+// errors surface at startup rather than on first use
+func (r *Registry) Preload(ctx context.Context, rdb redis.Scripter) error {
+	for _, s := range r.Scripts() {
+		if err := rdb.ScriptLoad(ctx, s.Source).Err(); err != nil {
+			return wrap(s, err)
+		}
+	}
+	return nil
+}
+
+// Run calls the named script with EVALSHA. If the server no longer has it
+// cached the script is loaded again and the call retried once.
+func (r *Registry) Run(ctx context.Context, rdb redis.Scripter, name string, keys []string, args ...interface{}) *redis.Cmd {
+	s, ok := r.Get(name)
+	if !ok {
+		cmd := redis.NewCmd(ctx)
+		cmd.SetErr(fmt.Errorf("scripting: no script named %q", name))
+		return cmd
+	}
+
+	cmd := rdb.EvalSha(ctx, s.SHA, keys, args...)
+	if err := cmd.Err(); err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		r.reloads.Add(1)
+		if err := rdb.ScriptLoad(ctx, s.Source).Err(); err != nil {
+			cmd.SetErr(wrap(s, err))
+			return cmd
+		}
+		cmd = rdb.EvalSha(ctx, s.SHA, keys, args...)
+	}
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		cmd.SetErr(wrap(s, err))
+	}
+	return cmd
+}
+
+// Int64 runs the named script and returns its integer reply
+func (r *Registry) Int64(ctx context.Context, rdb redis.Scripter, name string, keys []string, args ...interface{}) (int64, error) {
+	return r.Run(ctx, rdb, name, keys, args...).Int64()
+}
+
+// Text runs the named script and returns its string reply
+func (r *Registry) Text(ctx context.Context, rdb redis.Scripter, name string, keys []string, args ...interface{}) (string, error) {
+	return r.Run(ctx, rdb, name, keys, args...).Text()
+}
+
+// Bool runs the named script and returns its reply as a boolean, where Lua
+// true and 1 are true
+func (r *Registry) Bool(ctx context.Context, rdb redis.Scripter, name string, keys []string, args ...interface{}) (bool, error) {
+	return r.Run(ctx, rdb, name, keys, args...).Bool()
+}
+
+// StringSlice runs the named script and returns its array reply as strings
+func (r *Registry) StringSlice(ctx context.Context, rdb redis.Scripter, name string, keys []string, args ...interface{}) ([]string, error) {
+	return r.Run(ctx, rdb, name, keys, args...).StringSlice()
+}
+
+// Compile and runtime errors mention the line as "user_script:<n>:"
+var lineRe = regexp.MustCompile(`user_script:(\d+):`)
+
+// wrap attaches the script, and the line number when there is one, to err
+func wrap(s *Script, err error) error {
+	e := &ScriptError{Script: s, Err: err}
+	if m := lineRe.FindStringSubmatch(err.Error()); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
@@ -0,0 +1,381 @@
+// Package seed populates Redis with a reproducible synthetic keyspace of
+// users, posts, follows, scores and feeds to experiment on, and removes
+// exactly the keys it created.
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Schema holds the key-name patterns, each taking the numeric ID, except
+// Scores which names a single sorted set
+type Schema struct {
+	User    string // hash of profile fields
+	Post    string // string holding a JSON post
+	Follows string // set of followed user IDs
+	Feed    string // list of post IDs, newest first
+	Scores  string // sorted set of user IDs by score
+}
+
+// DefaultSchema is the key layout used for the fields of Options.Schema
+// that are left empty
+var DefaultSchema = Schema{
+	User:    "user:%d",
+	Post:    "post:%d",
+	Follows: "user:%d:follows",
+	Feed:    "user:%d:feed",
+	Scores:  "scores",
+}
+
+// withDefaults fills each empty field from DefaultSchema
+func (s Schema) withDefaults() Schema {
+	fill := func(field *string, def string) {
+		if *field == "" {
+			*field = def
+		}
+	}
+	fill(&s.User, DefaultSchema.User)
+	fill(&s.Post, DefaultSchema.Post)
+	fill(&s.Follows, DefaultSchema.Follows)
+	fill(&s.Feed, DefaultSchema.Feed)
+	fill(&s.Scores, DefaultSchema.Scores)
+	return s
+}
+
+// Validate checks that each ID pattern takes exactly one %d, that Scores
+// is set and that no two names are equal, since keys from colliding
+// patterns would overwrite each other
+func (s Schema) Validate() error {
+	patterns := []struct{ name, pattern string }{
+		{"user", s.User}, {"post", s.Post}, {"follows", s.Follows}, {"feed", s.Feed},
+	}
+	for _, p := range patterns {
+		if strings.Count(p.pattern, "%") != 1 || !strings.Contains(p.pattern, "%d") {
+			return fmt.Errorf("seed: %s key pattern %q must contain exactly one %%d and no other verb", p.name, p.pattern)
+		}
+	}
+	if strings.TrimSpace(s.Scores) == "" {
+		return errors.New("seed: the scores key name is required")
+	}
+
+	names := append(patterns, struct{ name, pattern string }{"scores", s.Scores})
+	for i, a := range names {
+		for _, b := range names[i+1:] {
+			if a.pattern == b.pattern {
+				return fmt.Errorf("seed: %s and %s keys are both %q", a.name, b.name, a.pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// Options controls the size, randomness and naming of the seeded data
+type Options struct {
+	Users          int
+	PostsPerUser   int
+	FollowsPerUser int
+	FeedLength     int
+	// TTLPercent is the share of posts and feeds that get an expiry
+	TTLPercent int
+	TTL        time.Duration
+	// Seed makes runs reproducible, the same seed yields the same data
+	Seed int64
+	// Prefix is prepended to every key, e.g. "seed:"
+	Prefix    string
+	Schema    Schema
+	BatchSize int
+}
+
+// DefaultOptions returns a small keyspace under the "seed:" prefix
+func DefaultOptions() Options {
+	return Options{
+		Users:          1000,
+		PostsPerUser:   5,
+		FollowsPerUser: 20,
+		FeedLength:     50,
+		TTLPercent:     20,
+		TTL:            time.Hour,
+		Seed:           1,
+		Prefix:         "seed:",
+		Schema:         DefaultSchema,
+		BatchSize:      100,
+	}
+}
+
+// Progress is called after each batch with the users written so far
+type Progress func(done, total int)
+
+// Stats summarises a seed or unseed run
+type Stats struct {
+	Keys     int64
+	Expiring int64
+	Duration time.Duration
+}
+
+// ErrAlreadySeeded is returned when the prefix already holds a manifest
+var ErrAlreadySeeded = errors.New("seed: prefix already seeded, run unseed first")
+
+// ManifestKey is the set recording every key created under prefix
+func ManifestKey(prefix string) string {
+	return prefix + "manifest"
+}
+
+var (
+	firstNames = []string{"Ava", "Ben", "Chloe", "Dev", "Elif", "Farah", "Gus", "Hana", "Ivan", "Jun", "Kemi", "Liam", "Mia", "Noor", "Omar", "Priya"}
+	lastNames  = []string{"Ahmed", "Berg", "Costa", "Diaz", "Evans", "Fischer", "Gupta", "Hoang", "Ito", "Jensen", "Khan", "Lopez", "Moreau", "Okafor", "Patel"}
+	countries  = []string{"BD", "DE", "NG", "JP", "CA", "BR", "AU", "KE", "PT", "US"}
+	words      = []string{"redis", "cache", "stream", "queue", "latency", "cluster", "pipeline", "replica", "shard", "index", "lock", "feed"}
+)
+
+// Seed writes the keyspace described by opts through pipelines, one batch of
+// users at a time, recording every key in the manifest. It refuses to touch
+// keys that already exist so that Unseed removes exactly what Seed created.
+func Seed(ctx context.Context, rdb *redis.Client, opts Options, progress Progress) (Stats, error) {
+	var stats Stats
+	start := time.Now()
+
+	if opts.Prefix == "" {
+		return stats, errors.New("seed: a key prefix is required")
+	}
+	opts.Schema = opts.Schema.withDefaults()
+	if err := opts.Schema.Validate(); err != nil {
+		return stats, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	manifest := ManifestKey(opts.Prefix)
+	n, err := rdb.Exists(ctx, manifest).Result()
+	if err != nil {
+		return stats, err
+	}
+	if n > 0 {
+		return stats, ErrAlreadySeeded
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	scores := opts.Prefix + opts.Schema.Scores
+	if err := checkFree(ctx, rdb, []string{scores}); err != nil {
+		return stats, err
+	}
+	if err := rdb.SAdd(ctx, manifest, scores).Err(); err != nil {
+		return stats, err
+	}
+	stats.Keys++
+
+	for first := 1; first <= opts.Users; first += opts.BatchSize {
+		last := min(first+opts.BatchSize-1, opts.Users)
+
+		b := newBatch(ctx, opts, rng)
+		for id := first; id <= last; id++ {
+			b.addUser(id, scores)
+		}
+
+		if err := checkFree(ctx, rdb, b.keys); err != nil {
+			return stats, err
+		}
+		if _, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, write := range b.writes {
+				write(pipe)
+			}
+			pipe.SAdd(ctx, manifest, b.keys)
+			return nil
+		}); err != nil {
+			return stats, err
+		}
+
+		stats.Keys += int64(len(b.keys))
+		stats.Expiring += b.expiring
+		if progress != nil {
+			progress(last, opts.Users)
+		}
+	}
+
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
+
+// checkFree fails if any of keys already exists
+func checkFree(ctx context.Context, rdb *redis.Client, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	n, err := rdb.Exists(ctx, keys...).Result()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("seed: %d of the keys to create already exist, choose another prefix", n)
+	}
+	return nil
+}
+
+// batch collects the pipelined writes and created keys for a range of users
+type batch struct {
+	ctx      context.Context
+	opts     Options
+	rng      *rand.Rand
+	keys     []string
+	writes   []func(pipe redis.Pipeliner)
+	expiring int64
+}
+
+func newBatch(ctx context.Context, opts Options, rng *rand.Rand) *batch {
+	return &batch{ctx: ctx, opts: opts, rng: rng}
+}
+
+func (b *batch) key(pattern string, id int) string {
+	return b.opts.Prefix + fmt.Sprintf(pattern, id)
+}
+
+func (b *batch) write(fn func(pipe redis.Pipeliner)) {
+	b.writes = append(b.writes, fn)
+}
+
+// ttl returns the TTL for a key, or 0 when it should not expire
+func (b *batch) ttl() time.Duration {
+	if b.rng.Intn(100) < b.opts.TTLPercent {
+		b.expiring++
+		return b.opts.TTL
+	}
+	return 0
+}
+
+func (b *batch) addUser(id int, scores string) {
+	ctx := b.ctx
+	opts := b.opts
+	rng := b.rng
+
+	// Profile
+	user := b.key(opts.Schema.User, id)
+	first, last := firstNames[rng.Intn(len(firstNames))], lastNames[rng.Intn(len(lastNames))]
+	profile := map[string]interface{}{
+		"name":    first + " " + last,
+		"email":   fmt.Sprintf("user%d@example.com", id),
+		"country": countries[rng.Intn(len(countries))],
+		"joined":  time.Unix(1600000000+rng.Int63n(100000000), 0).UTC().Format(time.RFC3339),
+	}
+	b.keys = append(b.keys, user)
+	b.write(func(pipe redis.Pipeliner) { pipe.HSet(ctx, user, profile) })
+
+	// Posts
+	for i := 0; i < opts.PostsPerUser; i++ {
+		postID := (id-1)*opts.PostsPerUser + i + 1
+		post := b.key(opts.Schema.Post, postID)
+		body, _ := json.Marshal(map[string]interface{}{
+			"author": id,
+			"title":  words[rng.Intn(len(words))] + " " + words[rng.Intn(len(words))],
+			"likes":  rng.Intn(500),
+		})
+		ttl := b.ttl()
+		b.keys = append(b.keys, post)
+		b.write(func(pipe redis.Pipeliner) { pipe.Set(ctx, post, body, ttl) })
+	}
+
+	// Follows
+	if opts.FollowsPerUser > 0 && opts.Users > 1 {
+		follows := b.key(opts.Schema.Follows, id)
+		var followed []interface{}
+		for i := 0; i < opts.FollowsPerUser; i++ {
+			if other := rng.Intn(opts.Users) + 1; other != id {
+				followed = append(followed, other)
+			}
+		}
+		if len(followed) > 0 {
+			b.keys = append(b.keys, follows)
+			b.write(func(pipe redis.Pipeliner) { pipe.SAdd(ctx, follows, followed...) })
+		}
+	}
+
+	// Score
+	score := float64(rng.Intn(10000))
+	b.write(func(pipe redis.Pipeliner) {
+		pipe.ZAdd(ctx, scores, redis.Z{Score: score, Member: strconv.Itoa(id)})
+	})
+
+	// Feed
+	if opts.FeedLength > 0 && opts.PostsPerUser > 0 {
+		feed := b.key(opts.Schema.Feed, id)
+		total := opts.Users * opts.PostsPerUser
+		entries := make([]interface{}, opts.FeedLength)
+		for i := range entries {
+			entries[i] = rng.Intn(total) + 1
+		}
+		ttl := b.ttl()
+		b.keys = append(b.keys, feed)
+		b.write(func(pipe redis.Pipeliner) {
+			pipe.LPush(ctx, feed, entries...)
+			if ttl > 0 {
+				pipe.Expire(ctx, feed, ttl)
+			}
+		})
+	}
+}
+
+// Unseed deletes every key recorded in the manifest under prefix, then the
+// manifest itself. Keys that already expired are simply skipped by DEL.
+func Unseed(ctx context.Context, rdb *redis.Client, prefix string, batchSize int, progress Progress) (Stats, error) {
+	var stats Stats
+	start := time.Now()
+	manifest := ManifestKey(prefix)
+
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	total, err := rdb.SCard(ctx, manifest).Result()
+	if err != nil {
+		return stats, err
+	}
+
+	done := 0
+	iter := rdb.SScan(ctx, manifest, 0, "", int64(batchSize)).Iterator()
+	var keys []string
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		n, err := rdb.Del(ctx, keys...).Result()
+		if err != nil {
+			return err
+		}
+		stats.Keys += n
+		done += len(keys)
+		keys = keys[:0]
+		if progress != nil {
+			progress(done, int(total))
+		}
+		return nil
+	}
+
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == batchSize {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return stats, err
+	}
+	if err := flush(); err != nil {
+		return stats, err
+	}
+
+	if err := rdb.Del(ctx, manifest).Err(); err != nil {
+		return stats, err
+	}
+	stats.Duration = time.Since(start)
+	return stats, nil
+}
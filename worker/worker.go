@@ -0,0 +1,419 @@
+// Package worker consumes a Redis stream through a consumer group with a
+// pool of concurrent handlers. Successful entries are acknowledged, failed
+// ones are retried with backoff based on their delivery count, and entries
+// that keep failing are moved to a dead-letter stream with the error attached.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Handler processes one stream entry. Returning an error leaves the entry
+// pending so it is retried later.
+type Handler func(ctx context.Context, msg redis.XMessage) error
+
+// Options configures a Worker
+type Options struct {
+	Stream   string
+	Group    string
+	Consumer string
+
+	// Concurrency is the number of entries handled at once
+	Concurrency int
+	// MaxAttempts is how many deliveries an entry gets before it is dead-lettered
+	MaxAttempts int
+	// Backoff returns how long an entry that has been delivered attempts
+	// times must sit idle before it is retried
+	Backoff func(attempts int64) time.Duration
+	// ClaimIdle is how long an entry held by another consumer must be idle
+	// before it is considered abandoned and claimed
+	ClaimIdle time.Duration
+	// DeadLetterStream receives entries that ran out of attempts, it defaults
+	// to Stream + ":dead"
+	DeadLetterStream string
+
+	// Block bounds each XREADGROUP call, and so how quickly Run notices shutdown
+	Block time.Duration
+	// RetryInterval is how often pending entries are scanned for retries
+	RetryInterval time.Duration
+}
+
+// ExponentialBackoff doubles base for every delivery, up to max
+func ExponentialBackoff(base, max time.Duration) func(int64) time.Duration {
+	return func(attempts int64) time.Duration {
+		d := base
+		for i := int64(1); i < attempts && d < max; i++ {
+			d *= 2
+		}
+		return min(d, max)
+	}
+}
+
+// Stats counts what a Worker has done so far
+type Stats struct {
+	Processed    int64
+	Failed       int64
+	Retried      int64
+	Claimed      int64
+	DeadLettered int64
+}
+
+// Worker runs a handler pool on a consumer group
+type Worker struct {
+	rdb     *redis.Client
+	opts    Options
+	handler Handler
+
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	mu       sync.Mutex
+	inflight map[string]bool
+
+	processed, failed, retried, claimed, deadLettered atomic.Int64
+}
+
+// New returns a Worker for opts, filling in defaults for unset fields
+func New(rdb *redis.Client, opts Options, handler Handler) *Worker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Backoff == nil {
+		opts.Backoff = ExponentialBackoff(100*time.Millisecond, 30*time.Second)
+	}
+	if opts.ClaimIdle <= 0 {
+		opts.ClaimIdle = 30 * time.Second
+	}
+	if opts.DeadLetterStream == "" {
+		opts.DeadLetterStream = opts.Stream + ":dead"
+	}
+	if opts.Block <= 0 {
+		opts.Block = time.Second
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = 500 * time.Millisecond
+	}
+
+	return &Worker{
+		rdb:      rdb,
+		opts:     opts,
+		handler:  handler,
+		slots:    make(chan struct{}, opts.Concurrency),
+		inflight: make(map[string]bool),
+	}
+}
+
+// Stats returns a snapshot of the worker's counters
+func (w *Worker) Stats() Stats {
+	return Stats{
+		Processed:    w.processed.Load(),
+		Failed:       w.failed.Load(),
+		Retried:      w.retried.Load(),
+		Claimed:      w.claimed.Load(),
+		DeadLettered: w.deadLettered.Load(),
+	}
+}
+
+// Run creates the consumer group if needed and processes entries until ctx
+// is cancelled. It then stops fetching, waits for in-flight handlers to
+// finish and returns. Handlers run with a context that is not cancelled by
+// shutdown, so in-flight work can complete.
+func (w *Worker) Run(ctx context.Context) error {
+	err := w.rdb.XGroupCreateMkStream(ctx, w.opts.Stream, w.opts.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	// A failing loop stops the other one too
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var loops sync.WaitGroup
+	errs := make(chan error, 2)
+	for _, loop := range []func(context.Context) error{w.fetchLoop, w.retryLoop} {
+		loops.Add(1)
+		go func(loop func(context.Context) error) {
+			defer loops.Done()
+			if err := loop(ctx); err != nil {
+				errs <- err
+				cancel()
+			}
+		}(loop)
+	}
+
+	loops.Wait()
+	w.wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+// fetchLoop reads new entries whenever a handler slot is free
+func (w *Worker) fetchLoop(ctx context.Context) error {
+	for ctx.Err() == nil {
+		free := w.acquire(ctx)
+		if free == 0 {
+			return nil
+		}
+
+		res, err := w.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    w.opts.Group,
+			Consumer: w.opts.Consumer,
+			Streams:  []string{w.opts.Stream, ">"},
+			Count:    int64(free),
+			Block:    w.opts.Block,
+		}).Result()
+		if err != nil {
+			w.release(free)
+			if err == redis.Nil || ctx.Err() != nil {
+				continue
+			}
+			return fmt.Errorf("worker: XREADGROUP: %w", err)
+		}
+
+		var msgs []redis.XMessage
+		for _, s := range res {
+			msgs = append(msgs, s.Messages...)
+		}
+		w.release(free - len(msgs))
+		for _, msg := range msgs {
+			w.dispatch(msg, 1)
+		}
+	}
+	return nil
+}
+
+// acquire takes every currently free slot, waiting for at least one
+func (w *Worker) acquire(ctx context.Context) int {
+	select {
+	case w.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0
+	}
+	n := 1
+	for n < w.opts.Concurrency {
+		select {
+		case w.slots <- struct{}{}:
+			n++
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+func (w *Worker) release(n int) {
+	for i := 0; i < n; i++ {
+		<-w.slots
+	}
+}
+
+// dispatch runs the handler for msg in its own goroutine. The caller must
+// already hold a slot, which is released when the handler returns.
+func (w *Worker) dispatch(msg redis.XMessage, attempt int64) {
+	w.mu.Lock()
+	w.inflight[msg.ID] = true
+	w.mu.Unlock()
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer w.release(1)
+		defer func() {
+			w.mu.Lock()
+			delete(w.inflight, msg.ID)
+			w.mu.Unlock()
+		}()
+
+		// Redis calls here must finish even during shutdown
+		ctx := context.Background()
+
+		err := w.handle(ctx, msg)
+		if err == nil {
+			w.processed.Add(1)
+			w.rdb.XAck(ctx, w.opts.Stream, w.opts.Group, msg.ID)
+			return
+		}
+
+		w.failed.Add(1)
+		if attempt >= int64(w.opts.MaxAttempts) {
+			w.deadLetter(ctx, msg, attempt, err.Error())
+		}
+		// Otherwise the entry stays pending and retryLoop picks it up
+	}()
+}
+
+// handle calls the handler, turning a panic into an error
+func (w *Worker) handle(ctx context.Context, msg redis.XMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return w.handler(ctx, msg)
+}
+
+// retryLoop periodically scans the pending entries list. Entries whose
+// backoff has elapsed are claimed and handled again, entries abandoned by
+// other consumers are claimed once ClaimIdle has passed, and entries that
+// are out of attempts are dead-lettered.
+func (w *Worker) retryLoop(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.RetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := w.retryPending(ctx); err != nil && ctx.Err() == nil {
+			return err
+		}
+	}
+}
+
+// pendingPage is how many pending entries retryPending reads at a time
+const pendingPage = 100
+
+// retryPending walks the whole pending entries list a page at a time
+func (w *Worker) retryPending(ctx context.Context) error {
+	start := "-"
+	for ctx.Err() == nil {
+		pending, err := w.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: w.opts.Stream,
+			Group:  w.opts.Group,
+			Start:  start,
+			End:    "+",
+			Count:  pendingPage,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("worker: XPENDING: %w", err)
+		}
+		if err := w.retryPage(ctx, pending); err != nil {
+			return err
+		}
+		if len(pending) < pendingPage {
+			return nil
+		}
+		start = nextID(pending[len(pending)-1].ID)
+	}
+	return nil
+}
+
+// nextID returns the smallest stream ID after id, for an exclusive start
+// that also works before Redis 6.2
+func nextID(id string) string {
+	ms, seq, _ := strings.Cut(id, "-")
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return id
+	}
+	return ms + "-" + strconv.FormatUint(n+1, 10)
+}
+
+// retryPage retries, claims or dead-letters the entries of one page of the
+// pending entries list
+func (w *Worker) retryPage(ctx context.Context, pending []redis.XPendingExt) error {
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		w.mu.Lock()
+		busy := w.inflight[p.ID]
+		w.mu.Unlock()
+		if busy {
+			continue
+		}
+
+		minIdle := w.opts.Backoff(p.RetryCount)
+		if p.Consumer != w.opts.Consumer {
+			minIdle = max(minIdle, w.opts.ClaimIdle)
+		}
+		if p.Idle < minIdle {
+			continue
+		}
+
+		if p.RetryCount >= int64(w.opts.MaxAttempts) {
+			msgs, err := w.rdb.XRange(ctx, w.opts.Stream, p.ID, p.ID).Result()
+			if err != nil {
+				return err
+			}
+			reason := fmt.Sprintf("abandoned by %s after %d deliveries", p.Consumer, p.RetryCount)
+			if len(msgs) == 0 {
+				// The entry was trimmed away, nothing left to retry
+				w.rdb.XAck(ctx, w.opts.Stream, w.opts.Group, p.ID)
+				continue
+			}
+			w.deadLetter(ctx, msgs[0], p.RetryCount, reason)
+			continue
+		}
+
+		// Wait for a slot. fetchLoop holds every free slot while it blocks
+		// in XREADGROUP, so giving up here would starve retries.
+		select {
+		case w.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil
+		}
+
+		msgs, err := w.rdb.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   w.opts.Stream,
+			Group:    w.opts.Group,
+			Consumer: w.opts.Consumer,
+			MinIdle:  minIdle,
+			Messages: []string{p.ID},
+		}).Result()
+		if err != nil || len(msgs) == 0 {
+			// Someone else claimed it first, or it no longer exists
+			w.release(1)
+			continue
+		}
+
+		if p.Consumer == w.opts.Consumer {
+			w.retried.Add(1)
+		} else {
+			w.claimed.Add(1)
+		}
+		w.dispatch(msgs[0], p.RetryCount+1)
+	}
+	return nil
+}
+
+// deadLetter copies msg to the dead-letter stream with the failure details
+// and acknowledges it, in one transaction
+func (w *Worker) deadLetter(ctx context.Context, msg redis.XMessage, attempts int64, reason string) {
+	values := make(map[string]interface{}, len(msg.Values)+4)
+	for k, v := range msg.Values {
+		values[k] = v
+	}
+	values["dlq_error"] = reason
+	values["dlq_attempts"] = strconv.FormatInt(attempts, 10)
+	values["dlq_id"] = msg.ID
+	values["dlq_consumer"] = w.opts.Consumer
+
+	_, err := w.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: w.opts.DeadLetterStream, Values: values})
+		pipe.XAck(ctx, w.opts.Stream, w.opts.Group, msg.ID)
+		return nil
+	})
+	if err == nil {
+		w.deadLettered.Add(1)
+	}
+}
@@ -0,0 +1,378 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	stream = "jobs"
+	group  = "workers"
+)
+
+func newClient(t *testing.T) *redis.Client {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return rdb
+}
+
+// testOptions returns options with timings short enough for tests
+func testOptions(consumer string) Options {
+	return Options{
+		Stream:        stream,
+		Group:         group,
+		Consumer:      consumer,
+		Concurrency:   2,
+		MaxAttempts:   3,
+		Backoff:       ExponentialBackoff(20*time.Millisecond, time.Second),
+		ClaimIdle:     100 * time.Millisecond,
+		Block:         20 * time.Millisecond,
+		RetryInterval: 5 * time.Millisecond,
+	}
+}
+
+func addJobs(t *testing.T, rdb *redis.Client, n int) []string {
+	t.Helper()
+	ids := make([]string, n)
+	for i := range ids {
+		id, err := rdb.XAdd(context.Background(), &redis.XAddArgs{
+			Stream: stream,
+			Values: map[string]interface{}{"job": i + 1},
+		}).Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// start runs w until the returned stop function is called, which waits for
+// Run to return and fails the test on an error
+func start(t *testing.T, w *Worker) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	return func() {
+		t.Helper()
+		cancel()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Run: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after cancel")
+		}
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func pendingCount(t *testing.T, rdb *redis.Client) int64 {
+	t.Helper()
+	p, err := rdb.XPending(context.Background(), stream, group).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Count
+}
+
+func TestAck(t *testing.T) {
+	rdb := newClient(t)
+	addJobs(t, rdb, 5)
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	w := New(rdb, testOptions("c1"), func(ctx context.Context, msg redis.XMessage) error {
+		mu.Lock()
+		seen[msg.ID]++
+		mu.Unlock()
+		return nil
+	})
+	stop := start(t, w)
+	waitFor(t, "5 processed", func() bool { return w.Stats().Processed == 5 })
+	stop()
+
+	if len(seen) != 5 {
+		t.Errorf("handled %d distinct entries, want 5", len(seen))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("%s handled %d times", id, n)
+		}
+	}
+	if n := pendingCount(t, rdb); n != 0 {
+		t.Errorf("%d entries still pending after ack", n)
+	}
+	if s := w.Stats(); s.Failed != 0 || s.Retried != 0 || s.DeadLettered != 0 {
+		t.Errorf("stats = %+v, want only processed entries", s)
+	}
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	rdb := newClient(t)
+	addJobs(t, rdb, 1)
+
+	opts := testOptions("c1")
+	var mu sync.Mutex
+	var backoffFor []int64
+	backoff := opts.Backoff
+	opts.Backoff = func(attempts int64) time.Duration {
+		mu.Lock()
+		backoffFor = append(backoffFor, attempts)
+		mu.Unlock()
+		return backoff(attempts)
+	}
+
+	var deliveries []time.Time
+	w := New(rdb, opts, func(ctx context.Context, msg redis.XMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deliveries = append(deliveries, time.Now())
+		if len(deliveries) < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	stop := start(t, w)
+	waitFor(t, "success on the third delivery", func() bool { return w.Stats().Processed == 1 })
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(deliveries) != 3 {
+		t.Fatalf("%d deliveries, want 3", len(deliveries))
+	}
+	// The wait grows with the delivery count: 20ms after the first, 40ms
+	// after the second. Idle time counts from the server's delivery, a
+	// little before the handler ran, hence the tolerance.
+	const tolerance = 2 * time.Millisecond
+	for i, want := range []time.Duration{20 * time.Millisecond, 40 * time.Millisecond} {
+		if gap := deliveries[i+1].Sub(deliveries[i]); gap < want-tolerance {
+			t.Errorf("retry %d came after %v, want at least %v", i+1, gap, want)
+		}
+	}
+	seen := make(map[int64]bool)
+	for _, a := range backoffFor {
+		seen[a] = true
+	}
+	if !seen[1] || !seen[2] {
+		t.Errorf("backoff asked for delivery counts %v, want 1 and 2", backoffFor)
+	}
+	if s := w.Stats(); s.Failed != 2 || s.Retried != 2 || s.DeadLettered != 0 {
+		t.Errorf("stats = %+v, want 2 failed and 2 retried", s)
+	}
+	if n := pendingCount(t, rdb); n != 0 {
+		t.Errorf("%d entries still pending", n)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	rdb := newClient(t)
+	ids := addJobs(t, rdb, 1)
+
+	var mu sync.Mutex
+	attempts := 0
+	w := New(rdb, testOptions("c1"), func(ctx context.Context, msg redis.XMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return fmt.Errorf("attempt %d failed", attempts)
+	})
+	stop := start(t, w)
+	waitFor(t, "dead letter", func() bool { return w.Stats().DeadLettered == 1 })
+	stop()
+
+	if attempts != 3 {
+		t.Errorf("handler ran %d times, want MaxAttempts 3", attempts)
+	}
+	dead, err := rdb.XRange(context.Background(), stream+":dead", "-", "+").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 1 {
+		t.Fatalf("dead-letter stream has %d entries, want 1", len(dead))
+	}
+	v := dead[0].Values
+	if v["job"] != "1" || v["dlq_id"] != ids[0] || v["dlq_attempts"] != "3" ||
+		v["dlq_error"] != "attempt 3 failed" || v["dlq_consumer"] != "c1" {
+		t.Errorf("dead-letter entry = %v", v)
+	}
+	if n := pendingCount(t, rdb); n != 0 {
+		t.Errorf("%d entries still pending after dead-lettering", n)
+	}
+}
+
+func TestClaimFromCrashedConsumer(t *testing.T) {
+	rdb := newClient(t)
+	ctx := context.Background()
+	ids := addJobs(t, rdb, 3)
+
+	// The crashed consumer reads every entry and never acknowledges them
+	if err := rdb.XGroupCreate(ctx, stream, group, "0").Err(); err != nil {
+		t.Fatal(err)
+	}
+	crashed, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: group, Consumer: "crashed", Streams: []string{stream, ">"}, Count: 10,
+	}).Result()
+	if err != nil || len(crashed[0].Messages) != 3 {
+		t.Fatalf("crashed consumer read %v, %v", crashed, err)
+	}
+	crashedAt := time.Now()
+
+	var mu sync.Mutex
+	handled := make(map[string]time.Time)
+	w := New(rdb, testOptions("survivor"), func(ctx context.Context, msg redis.XMessage) error {
+		mu.Lock()
+		handled[msg.ID] = time.Now()
+		mu.Unlock()
+		return nil
+	})
+	stop := start(t, w)
+	waitFor(t, "claimed entries processed", func() bool { return w.Stats().Processed == 3 })
+	stop()
+
+	for _, id := range ids {
+		at, ok := handled[id]
+		if !ok {
+			t.Errorf("%s was never claimed", id)
+			continue
+		}
+		if idle := at.Sub(crashedAt); idle < 100*time.Millisecond {
+			t.Errorf("%s claimed after %v, before ClaimIdle", id, idle)
+		}
+	}
+	if s := w.Stats(); s.Claimed != 3 || s.Retried != 0 {
+		t.Errorf("stats = %+v, want 3 claimed", s)
+	}
+	if n := pendingCount(t, rdb); n != 0 {
+		t.Errorf("%d entries still pending", n)
+	}
+}
+
+func TestClaimPastFirstPage(t *testing.T) {
+	rdb := newClient(t)
+	ctx := context.Background()
+	const n = pendingPage + 50
+	addJobs(t, rdb, n)
+
+	if err := rdb.XGroupCreate(ctx, stream, group, "0").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: group, Consumer: "crashed", Streams: []string{stream, ">"}, Count: n,
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := testOptions("survivor")
+	opts.Concurrency = 8
+	w := New(rdb, opts, func(ctx context.Context, msg redis.XMessage) error { return nil })
+	stop := start(t, w)
+	waitFor(t, "every entry claimed", func() bool { return w.Stats().Processed == n })
+	stop()
+
+	if s := w.Stats(); s.Claimed != n {
+		t.Errorf("claimed %d entries, want %d", s.Claimed, n)
+	}
+	if c := pendingCount(t, rdb); c != 0 {
+		t.Errorf("%d entries still pending", c)
+	}
+}
+
+func TestNextID(t *testing.T) {
+	for id, want := range map[string]string{
+		"1700000000000-0": "1700000000000-1",
+		"1700000000000-9": "1700000000000-10",
+	} {
+		if got := nextID(id); got != want {
+			t.Errorf("nextID(%s) = %s, want %s", id, got, want)
+		}
+	}
+}
+
+func TestDrainOnShutdown(t *testing.T) {
+	rdb := newClient(t)
+	addJobs(t, rdb, 2)
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	w := New(rdb, testOptions("c1"), func(ctx context.Context, msg redis.XMessage) error {
+		started <- struct{}{}
+		<-release
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+	<-started
+	<-started
+
+	// Shut down while both handlers are busy
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("Run returned while handlers were still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Entries added now must not be picked up
+	addJobs(t, rdb, 1)
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after handlers finished")
+	}
+
+	if s := w.Stats(); s.Processed != 2 || s.Failed != 0 {
+		t.Errorf("stats = %+v, want the 2 in-flight entries processed", s)
+	}
+	if n := pendingCount(t, rdb); n != 0 {
+		t.Errorf("%d entries still pending, in-flight work was not acknowledged", n)
+	}
+	length, _ := rdb.XLen(context.Background(), stream).Result()
+	if length != 3 {
+		t.Errorf("stream length %d, want 3", length)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	for attempts, want := range map[int64]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		3:  400 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		20: time.Second,
+	} {
+		if got := backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}